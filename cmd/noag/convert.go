@@ -0,0 +1,76 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+
+	"github.com/loig/noag/generator"
+)
+
+/*
+Read a network file and write it in another format
+*/
+func convertCommand(args []string) {
+	flags := flag.NewFlagSet("convert", flag.ExitOnError)
+	var common commonFlags
+	var withInteractionGraph bool
+	common.addInput(flags, outputFile)
+	common.addOutput(flags, "", "Path to output file (standard output if empty)")
+	common.addFormat(flags, formatDOT, "Format of the output file (json, dot or prism)")
+	common.addVerbose(flags)
+	flags.BoolVar(&withInteractionGraph, "interaction", false, "Add the interaction graph to the json output")
+	common.parse(flags, args)
+
+	writeNetwork(readNetwork(common.input), common.output, common.format, withInteractionGraph)
+}
+
+func readNetwork(inputFileName string) generator.Network {
+	log.Print("Reading network from ", inputFileName)
+	g, err := generator.ReadNetworkFile(inputFileName)
+	if err != nil {
+		log.Fatal("Error: ", err)
+	}
+	return g
+}
+
+func writeNetwork(g generator.Network, outputFileName, outputFormat string, withInteractionGraph bool) {
+	var write func(f io.Writer) error
+	switch outputFormat {
+	case formatJSON:
+		write = func(f io.Writer) error {
+			return g.WriteJSON(f, generator.JSONOptions{InteractionGraph: withInteractionGraph})
+		}
+	case formatDOT:
+		write = g.WriteDOT
+	case formatPRISM:
+		write = g.WritePRISM
+	default:
+		log.Fatal("Error: unknown output format (", outputFormat, ")")
+	}
+
+	log.Print("Writing automata into ", outputFileName)
+	f := create(outputFileName)
+	defer f.Close()
+	if err := write(f); err != nil {
+		log.Fatal("Error: cannot write to output file (", outputFileName, "): ", err)
+	}
+}
@@ -0,0 +1,232 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/loig/noag/generator"
+)
+
+/*
+Generate a network from a configuration file and write it,
+possibly with its hash and the analysis of its product
+*/
+func generateCommand(args []string) {
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	var common commonFlags
+	var conf configFlags
+	var withInteractionGraph bool
+	var printHash bool
+	var analyze bool
+	var budget int
+	var printConfig bool
+	var printStats bool
+	conf.add(flags)
+	flags.BoolVar(&printConfig, "print-config", false, "Print the effective configuration (after overrides and corrections) and exit")
+	common.addInput(flags, "")
+	flags.Lookup("in").Usage = "Path to a network file (json) to read instead of generating a network"
+	common.addOutput(flags, outputFile, "Path to output file")
+	common.addFormat(flags, formatJSON, "Format of the output file (json, dot or prism)")
+	common.addVerbose(flags)
+	flags.BoolVar(&withInteractionGraph, "interaction", false, "Add the interaction graph to the json output")
+	flags.BoolVar(&printHash, "hash", false, "Print the SHA-256 of the canonical json representation of the generated automata")
+	flags.BoolVar(&printStats, "stats", false, "Print statistics on the generated network (see the stats command)")
+	flags.BoolVar(&analyze, "analyze", false, "Explore the synchronous product of the generated network to look for a global goal state")
+	flags.IntVar(&budget, "budget", 1000000, "Maximum number of product states explored by -analyze (0 for no limit)")
+	common.parse(flags, args)
+
+	if printConfig {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(conf.newGenerator(conf.load()).Configuration()); err != nil {
+			log.Fatal("Error: cannot print the configuration")
+		}
+		return
+	}
+
+	var g generator.Network
+	if common.input != "" {
+		g = readNetwork(common.input)
+	} else {
+		var err error
+		g, err = conf.newGenerator(conf.load()).Generate()
+		if err != nil {
+			log.Fatal("Error: ", err)
+		}
+	}
+
+	if printStats {
+		stats := g.Statistics()
+		stats.Automata = nil
+		printStatistics(os.Stdout, stats)
+	}
+
+	if analyze {
+		printProduct(g, budget)
+	}
+
+	if printHash {
+		hash, err := g.Hash()
+		if err != nil {
+			log.Fatal("Error: cannot compute the hash of the network")
+		}
+		fmt.Println("SHA-256:", hash)
+	}
+
+	writeNetwork(g, common.output, common.format, withInteractionGraph)
+}
+
+/*
+Overrides of configuration fields given on the command line
+*/
+type settings []string
+
+func (s *settings) String() string {
+	return strings.Join(*s, " ")
+}
+
+func (s *settings) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expecting Field=Value")
+	}
+	*s = append(*s, value)
+	return nil
+}
+
+/*
+Seed given on the command line, 0 cannot be given as it
+stands for a time-based seed in configurations, so that the
+network could not be regenerated from its metadata
+*/
+type seedFlag int64
+
+func (s *seedFlag) String() string {
+	return strconv.FormatInt(int64(*s), 10)
+}
+
+func (s *seedFlag) Set(value string) error {
+	seed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("expecting an integer")
+	}
+	if seed == 0 {
+		return fmt.Errorf("0 stands for a time-based seed, it cannot be given explicitly")
+	}
+	*s = seedFlag(seed)
+	return nil
+}
+
+/*
+Flags giving the configuration of the generation
+*/
+type configFlags struct {
+	file      string
+	overrides settings
+	graph     string
+	seed      seedFlag
+	strict    bool
+	workers   int
+}
+
+func (c *configFlags) add(flags *flag.FlagSet) {
+	flags.StringVar(&c.file, "conf", configFile, "Path to configuration file")
+	flags.Var(&c.overrides, "set", "Override a field of the configuration (Field=Value, can be repeated)")
+	flags.StringVar(&c.graph, "graph", "", "Path to an interaction graph file (edge list or DOT) to use as topology")
+	flags.Var(&c.seed, "seed", "Seed for the random generation, instead of the one of the configuration file (not 0)")
+	flags.IntVar(&c.workers, "workers", 1, "Number of goroutines building the automata of a network")
+	flags.BoolVar(&c.strict, "strict", false, "Reject inconsistent configurations and unknown fields instead of correcting them")
+}
+
+/*
+Read the configuration file and apply, in this order, the environment
+variables, the overrides and the other flags of the command line
+*/
+func (c *configFlags) load() generator.Configuration {
+	readConfigurationFile := generator.ReadConfigurationFile
+	if c.strict {
+		readConfigurationFile = generator.ReadConfigurationFileStrict
+	}
+	config, err := readConfigurationFile(c.file)
+	if err != nil {
+		log.Fatal("Error: ", err)
+	}
+
+	setFromEnvironment := config.SetFromEnvironment
+	if c.strict {
+		setFromEnvironment = config.SetFromEnvironmentStrict
+	}
+	if err := setFromEnvironment(); err != nil {
+		log.Fatal("Error: ", err)
+	}
+	for _, setting := range c.overrides {
+		pos := strings.Index(setting, "=")
+		if err := config.Set(setting[:pos], setting[pos+1:]); err != nil {
+			log.Fatal("Error: ", err)
+		}
+	}
+
+	if c.graph != "" {
+		config.Topology = generator.TopologyFromFile
+		config.TopologyFile = c.graph
+	}
+
+	// the seed given on the command line has priority,
+	// a time-based seed is used if none is given
+	if c.seed != 0 {
+		config.Seed = int64(c.seed)
+	}
+	if config.Seed == 0 {
+		config.Seed = time.Now().UnixNano()
+	}
+	log.Print("Using seed ", config.Seed)
+
+	return config
+}
+
+/*
+Build a generator, in strict mode the command
+stops on any inconsistency of the configuration
+*/
+func (c *configFlags) newGenerator(config generator.Configuration) *generator.Generator {
+	var gen *generator.Generator
+	if !c.strict {
+		gen = generator.New(config, nil)
+	} else {
+		var err error
+		gen, err = generator.NewStrict(config, nil)
+		var configErr generator.ConfigurationError
+		if errors.As(err, &configErr) {
+			for _, violation := range configErr.Violations {
+				log.Print("Error: ", violation)
+			}
+			log.Fatal("Error: inconsistent configuration (", len(configErr.Violations), " violated constraints)")
+		}
+	}
+	gen.SetNumWorkers(c.workers)
+	return gen
+}
@@ -0,0 +1,193 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+/*
+Command noag generates random networks of automata and
+works on network files, see README.md for its usage.
+*/
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// default files
+const (
+	configFile = "conf.json"
+	outputFile = "out.json"
+)
+
+// output formats
+const (
+	formatJSON  = "json"
+	formatDOT   = "dot"
+	formatPRISM = "prism"
+	formatText  = "text"
+)
+
+/*
+A subcommand of noag, run with the arguments
+following its name
+*/
+type command struct {
+	name    string
+	aliases []string
+	summary string
+	run     func(args []string)
+}
+
+var commands = []command{
+	{name: "generate", summary: "generate a random network of automata", run: generateCommand},
+	{name: "suite", summary: "generate a family of networks from a sweep specification", run: suiteCommand},
+	{name: "validate", summary: "check a network file", run: validateCommand},
+	{name: "convert", summary: "convert a network file to another format", run: convertCommand},
+	{name: "stats", summary: "print statistics on a network file", run: statsCommand},
+	{name: "product", aliases: []string{"analyze"}, summary: "explore the synchronous product of a network file", run: productCommand},
+}
+
+func main() {
+	// without command, or with flags only, noag generates
+	// a network as in its first versions
+	if len(os.Args) < 2 || strings.HasPrefix(os.Args[1], "-") {
+		generateCommand(os.Args[1:])
+		return
+	}
+
+	name := os.Args[1]
+	if name == "help" {
+		usage(os.Stdout)
+		return
+	}
+	for _, c := range commands {
+		if c.name == name {
+			c.run(os.Args[2:])
+			return
+		}
+		for _, alias := range c.aliases {
+			if alias == name {
+				c.run(os.Args[2:])
+				return
+			}
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "noag: unknown command", name)
+	usage(os.Stderr)
+	os.Exit(2)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: noag <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		name := c.name
+		if len(c.aliases) > 0 {
+			name += " (" + strings.Join(c.aliases, ", ") + ")"
+		}
+		fmt.Fprintf(w, "  %-20s %s\n", name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Without command, noag generates a network (noag -conf conf.json -out out.json).")
+	fmt.Fprintln(w, "Use noag <command> -h for the flags of a command.")
+}
+
+/*
+Flags shared by the commands: input and output
+files, output format and verbosity
+*/
+type commonFlags struct {
+	input   string
+	output  string
+	format  string
+	verbose bool
+}
+
+func (c *commonFlags) addInput(flags *flag.FlagSet, defaultInput string) {
+	flags.StringVar(&c.input, "in", defaultInput, "Path to the network file (json) to read")
+}
+
+func (c *commonFlags) addOutput(flags *flag.FlagSet, defaultOutput, usage string) {
+	flags.StringVar(&c.output, "out", defaultOutput, usage)
+}
+
+func (c *commonFlags) addFormat(flags *flag.FlagSet, defaultFormat, usage string) {
+	flags.StringVar(&c.format, "format", defaultFormat, usage)
+}
+
+func (c *commonFlags) addVerbose(flags *flag.FlagSet) {
+	flags.BoolVar(&c.verbose, "v", false, "Log the progress of the command, not only warnings and errors")
+}
+
+/*
+Parse the arguments of a command and set the verbosity
+*/
+func (c *commonFlags) parse(flags *flag.FlagSet, args []string) {
+	flags.Parse(args)
+	if flags.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "noag", flags.Name()+": unexpected argument", flags.Arg(0))
+		flags.Usage()
+		os.Exit(2)
+	}
+	if !c.verbose {
+		log.SetOutput(quietWriter{w: os.Stderr})
+	}
+}
+
+/*
+Writer for the log which only keeps warnings and errors
+*/
+type quietWriter struct {
+	w io.Writer
+}
+
+func (qw quietWriter) Write(p []byte) (int, error) {
+	if bytes.Contains(p, []byte("Warning")) || bytes.Contains(p, []byte("Error")) {
+		return qw.w.Write(p)
+	}
+	return len(p), nil
+}
+
+/*
+Create an output file, or use the standard
+output if no file name is given
+*/
+func create(fileName string) io.WriteCloser {
+	if fileName == "" {
+		return nopCloser{os.Stdout}
+	}
+	f, err := os.Create(fileName)
+	if err != nil {
+		log.Fatal("Error: cannot write to output file (", fileName, ")")
+	}
+	return f
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,63 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/loig/noag/generator"
+)
+
+/*
+Explore the synchronous product of a network file
+*/
+func productCommand(args []string) {
+	flags := flag.NewFlagSet("product", flag.ExitOnError)
+	var common commonFlags
+	var budget int
+	common.addInput(flags, outputFile)
+	common.addVerbose(flags)
+	flags.IntVar(&budget, "budget", 1000000, "Maximum number of product states explored (0 for no limit)")
+	common.parse(flags, args)
+
+	printProduct(readNetwork(common.input), budget)
+}
+
+func printProduct(g generator.Network, budget int) {
+	log.Print("Exploring the synchronous product")
+	result := g.Product(budget)
+	switch {
+	case result.Reachable:
+		fmt.Println("Global goal reachable: yes")
+		fmt.Println("Shortest plan length:", result.PlanLength)
+		plan := make([]string, len(result.Plan))
+		for i, label := range result.Plan {
+			plan[i] = g.LabelName(label)
+		}
+		fmt.Println("Shortest plan:", strings.Join(plan, " "))
+	case result.Complete:
+		fmt.Println("Global goal reachable: no")
+	default:
+		fmt.Println("Global goal reachable: unknown (state budget exhausted)")
+	}
+	fmt.Println("Explored product states:", result.ExploredStates)
+}
@@ -0,0 +1,123 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/loig/noag/generator"
+)
+
+/*
+Print statistics on a network file
+*/
+func statsCommand(args []string) {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	var common commonFlags
+	var summary bool
+	common.addInput(flags, outputFile)
+	common.addOutput(flags, "", "Path to output file (standard output if empty)")
+	common.addFormat(flags, formatText, "Format of the output (text or json)")
+	common.addVerbose(flags)
+	flags.BoolVar(&summary, "summary", false, "Only give the aggregate statistics, not the ones of each automaton")
+	common.parse(flags, args)
+
+	if common.format != formatText && common.format != formatJSON {
+		log.Fatal("Error: unknown output format (", common.format, ")")
+	}
+
+	stats := readNetwork(common.input).Statistics()
+	if summary {
+		stats.Automata = nil
+	}
+
+	out := create(common.output)
+	defer out.Close()
+	if common.format == formatJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(stats); err != nil {
+			log.Fatal("Error: cannot write to output file (", common.output, ")")
+		}
+		return
+	}
+	printStatistics(out, stats)
+}
+
+/*
+Write statistics on a network in a human-readable form
+*/
+func printStatistics(w io.Writer, stats generator.Statistics) {
+	fmt.Fprintln(w, "Automata:", stats.NumAutomata)
+	fmt.Fprintln(w, "States:", stats.NumStates)
+	fmt.Fprintln(w, "Goal states:", stats.NumGoalStates)
+	fmt.Fprintln(w, "Transitions:", stats.NumTransitions)
+	fmt.Fprintf(w, "Silent transitions: %d (%.1f%%)\n", stats.NumSilentTransitions,
+		100*float64(stats.NumSilentTransitions)/math.Max(1, float64(stats.NumTransitions)))
+	fmt.Fprintln(w, "Labels:", stats.NumLabels)
+	fmt.Fprintf(w, "Private labels: %d (%.1f%%)\n", stats.NumPrivateLabels, 100*stats.PrivateLabelRatio)
+	fmt.Fprintf(w, "Shared labels: %d (%.1f%%)\n", stats.NumSharedLabels, 100*(1-stats.PrivateLabelRatio))
+	fmt.Fprintln(w, "Out-degrees of the states:", degrees(stats.OutDegrees))
+	fmt.Fprintln(w, "Interaction graph:")
+	fmt.Fprintln(w, "  Connected components:", stats.NumComponents)
+	fmt.Fprintln(w, "  Degrees:", degrees(stats.Degrees))
+	if stats.DiameterExact {
+		fmt.Fprintln(w, "  Diameter:", stats.Diameter)
+	} else {
+		fmt.Fprintln(w, "  Diameter: at least", stats.Diameter)
+	}
+	fmt.Fprintf(w, "  Clustering coefficient: %.4f\n", stats.ClusteringCoefficient)
+	exponent := math.Floor(stats.ProductSizeBoundLog10)
+	fmt.Fprintf(w, "Product states: at most %.3fe%d (10^%.2f)\n",
+		math.Pow(10, stats.ProductSizeBoundLog10-exponent), int(exponent), stats.ProductSizeBoundLog10)
+
+	if stats.Automata == nil {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%-12s %8s %6s %12s %7s %7s %8s %7s %14s %7s\n",
+		"automaton", "states", "goals", "transitions", "silent", "labels", "private", "shared", "out-degrees", "degree")
+	for _, a := range stats.Automata {
+		fmt.Fprintf(w, "%-12s %8d %6d %12d %7d %7d %8d %7d %14s %7d\n",
+			a.Name, a.NumStates, a.NumGoalStates, a.NumTransitions, a.NumSilentTransitions, a.NumLabels,
+			a.NumPrivateLabels, a.NumSharedLabels,
+			fmt.Sprintf("%d-%d (%.2f)", a.OutDegrees.Min, a.OutDegrees.Max, a.OutDegrees.Mean), a.Degree)
+	}
+}
+
+/*
+Summary of degree statistics: minimum, maximum,
+mean and distribution
+*/
+func degrees(stats generator.DegreeStatistics) string {
+	distribution := make([]string, 0)
+	for d, count := range stats.Distribution {
+		if count > 0 {
+			distribution = append(distribution, fmt.Sprint(d, ":", count))
+		}
+	}
+	return fmt.Sprintf("min %d, max %d, mean %.2f, distribution %s",
+		stats.Min, stats.Max, stats.Mean, strings.Join(distribution, " "))
+}
@@ -0,0 +1,211 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/loig/noag/generator"
+)
+
+// files of a suite
+const (
+	suiteDirectory   = "suite"
+	manifestJSONFile = "manifest.json"
+	manifestCSVFile  = "manifest.csv"
+)
+
+/*
+Entry of the manifest of a suite, for one generated network
+*/
+type manifestEntry struct {
+	File       string
+	Point      int
+	Parameters map[string]string
+	Seed       int64
+	Attempts   int
+	Hash       string
+	Error      string `json:",omitempty"`
+	Statistics *generator.Statistics
+}
+
+/*
+Generate a family of networks described by a sweep specification,
+in a directory tree with a manifest listing the generated files
+*/
+func suiteCommand(args []string) {
+	flags := flag.NewFlagSet("suite", flag.ExitOnError)
+	var common commonFlags
+	var conf configFlags
+	var sweepFileName string
+	conf.add(flags)
+	flags.Lookup("seed").Usage = "Base seed of the suite, instead of the one of the sweep file (not 0)"
+	flags.StringVar(&sweepFileName, "sweep", "sweep.json", "Path to the sweep specification file")
+	common.addOutput(flags, suiteDirectory, "Path to the output directory")
+	common.addFormat(flags, formatJSON, "Format of the generated files (json, dot or prism)")
+	common.addVerbose(flags)
+	common.parse(flags, args)
+
+	if common.format != formatJSON && common.format != formatDOT && common.format != formatPRISM {
+		log.Fatal("Error: unknown output format (", common.format, ")")
+	}
+
+	sweep, err := generator.ReadSweepFile(sweepFileName)
+	if err != nil {
+		log.Fatal("Error: ", err)
+	}
+	if conf.seed != 0 {
+		sweep.BaseSeed = int64(conf.seed)
+	}
+
+	base := conf.load()
+	instances, err := sweep.Instances(base)
+	if err != nil {
+		log.Fatal("Error: ", err)
+	}
+	log.Print("Generating ", len(instances), " networks into ", common.output)
+	if err := os.MkdirAll(common.output, 0755); err != nil {
+		log.Fatal("Error: cannot create directory ", common.output)
+	}
+
+	manifest := make([]manifestEntry, 0, len(instances))
+	for _, instance := range instances {
+		entry := manifestEntry{
+			File:       instanceFile(instance, common.format),
+			Point:      instance.Point,
+			Parameters: make(map[string]string),
+			Seed:       instance.Seed,
+		}
+		for _, setting := range instance.Settings {
+			entry.Parameters[setting.Field] = setting.Value
+		}
+
+		log.Print("Generating ", entry.File)
+		g, err := conf.newGenerator(instance.Configuration).Generate()
+		entry.Attempts = g.Attempts
+		if err != nil {
+			log.Print("Warning: ", entry.File, " not generated, ", err)
+			entry.Error = err.Error()
+			manifest = append(manifest, entry)
+			continue
+		}
+
+		entry.Hash, err = g.Hash()
+		if err != nil {
+			log.Fatal("Error: cannot compute the hash of the network")
+		}
+		stats := g.Statistics()
+		stats.Automata = nil
+		entry.Statistics = &stats
+
+		fileName := filepath.Join(common.output, entry.File)
+		if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+			log.Fatal("Error: cannot create directory ", filepath.Dir(fileName))
+		}
+		writeNetwork(g, fileName, common.format, false)
+		manifest = append(manifest, entry)
+	}
+
+	writeManifestJSON(filepath.Join(common.output, manifestJSONFile), manifest)
+	writeManifestCSV(filepath.Join(common.output, manifestCSVFile), manifest, sweep)
+	log.Print("Manifest written into ", filepath.Join(common.output, manifestJSONFile), " and ", manifestCSVFile)
+}
+
+/*
+Path of the file of an instance, relative to the directory of
+the suite: one directory level per parameter of the sweep
+*/
+func instanceFile(instance generator.SweepInstance, format string) string {
+	path := make([]string, 0, len(instance.Settings)+1)
+	for _, setting := range instance.Settings {
+		path = append(path, setting.Field+"-"+pathSafe(setting.Value))
+	}
+	path = append(path, fmt.Sprint("seed-", instance.Seed, ".", format))
+	return filepath.ToSlash(filepath.Join(path...))
+}
+
+/*
+Value usable as part of a file name
+*/
+func pathSafe(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, value)
+}
+
+func writeManifestJSON(fileName string, manifest []manifestEntry) {
+	f := create(fileName)
+	defer f.Close()
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		log.Fatal("Error: cannot write to manifest file (", fileName, ")")
+	}
+}
+
+func writeManifestCSV(fileName string, manifest []manifestEntry, sweep generator.Sweep) {
+	f := create(fileName)
+	defer f.Close()
+	w := csv.NewWriter(f)
+
+	header := []string{"file", "point"}
+	for _, parameter := range sweep.Parameters {
+		header = append(header, parameter.Field)
+	}
+	header = append(header, "seed", "attempts", "hash", "error",
+		"automata", "states", "goal_states", "transitions", "labels", "shared_labels", "private_labels",
+		"components", "diameter", "clustering_coefficient", "product_size_log10")
+	w.Write(header)
+
+	for _, entry := range manifest {
+		record := []string{entry.File, strconv.Itoa(entry.Point)}
+		for _, parameter := range sweep.Parameters {
+			record = append(record, entry.Parameters[parameter.Field])
+		}
+		record = append(record, strconv.FormatInt(entry.Seed, 10), strconv.Itoa(entry.Attempts), entry.Hash, entry.Error)
+		if stats := entry.Statistics; stats != nil {
+			for _, count := range []int{stats.NumAutomata, stats.NumStates, stats.NumGoalStates, stats.NumTransitions,
+				stats.NumLabels, stats.NumSharedLabels, stats.NumPrivateLabels, stats.NumComponents, stats.Diameter} {
+				record = append(record, strconv.Itoa(count))
+			}
+			record = append(record,
+				strconv.FormatFloat(stats.ClusteringCoefficient, 'f', 4, 64),
+				strconv.FormatFloat(stats.ProductSizeBoundLog10, 'f', 2, 64))
+		} else {
+			record = append(record, "", "", "", "", "", "", "", "", "", "", "")
+		}
+		w.Write(record)
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Fatal("Error: cannot write to manifest file (", fileName, ")")
+	}
+}
@@ -0,0 +1,66 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/loig/noag/generator"
+)
+
+/*
+Check a network file and write a json report, the
+exit status is 1 if the network is not valid
+*/
+func validateCommand(args []string) {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	var common commonFlags
+	common.addInput(flags, outputFile)
+	common.addOutput(flags, "", "Path to the report file (standard output if empty)")
+	common.addVerbose(flags)
+	common.parse(flags, args)
+
+	log.Print("Validating network from ", common.input)
+	jNetwork, err := generator.ReadJSONFile(common.input)
+	if err != nil {
+		log.Fatal("Error: ", err)
+	}
+	report := jNetwork.Validate()
+
+	out := create(common.output)
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal("Error: cannot write to report file (", common.output, ")")
+	}
+	out.Close()
+
+	if !report.Valid {
+		for _, check := range report.Checks {
+			for _, problem := range check.Problems {
+				log.Print("Error: ", check.Name, ": ", problem)
+			}
+		}
+		os.Exit(1)
+	}
+	log.Print("The network is valid")
+}
@@ -0,0 +1,510 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+/*
+Structure for representing automata.
+States are positive integers from 0 to numStates - 1.
+The initial state is always 0.
+*/
+type Automaton struct {
+	// optional, the automata are named after
+	// their position in their network otherwise
+	Name      string
+	NumStates int
+	// optional, the states are named after their number otherwise
+	StateNames  []string
+	Labels      []int
+	GoalStates  []int
+	Transitions []Transition
+}
+
+type Transition struct {
+	From  int
+	To    int
+	Label int
+	// cost of the transition, only meaningful in weighted
+	// networks, the same for all the transitions from a
+	// state with a label
+	Cost float64
+	// probability of the transition, only meaningful in
+	// probabilistic networks, the probabilities of the
+	// transitions from a state with a label sum to 1 and
+	// silent transitions have probability 1
+	Probability float64
+}
+
+/*
+Name of an automaton given its position in its network
+*/
+func (a Automaton) nameOf(id int) string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return fmt.Sprint(automatonName, id)
+}
+
+/*
+Add a new label to an automaton, with a transition
+using it between two random states
+*/
+func (gen *Generator) addLabel(a *Automaton, label int) {
+	r := gen.rand
+	a.Labels = append(a.Labels, label)
+	transition := Transition{
+		From:  r.Intn(a.NumStates),
+		To:    r.Intn(a.NumStates),
+		Label: label,
+	}
+	if gen.config.CostDistribution != CostNone {
+		transition.Cost = gen.drawCost(r, label)
+	}
+	// the only transition with this label
+	if gen.config.Determinism == DeterminismProbabilistic {
+		transition.Probability = 1
+	}
+	a.Transitions = append(a.Transitions, transition)
+}
+
+/*
+Name of a state of an automaton
+*/
+func (a Automaton) stateNameOf(state int) string {
+	if a.StateNames != nil {
+		return a.StateNames[state]
+	}
+	return fmt.Sprint(stateName, state)
+}
+
+/*
+Give additional targets to some of the transitions of an automaton:
+with probability BranchingProbability, a transition gets between 1
+and MaxNumSuccessors - 1 other targets, all distinct
+*/
+func (gen *Generator) branch(r *rand.Rand, transitions []Transition, numStates int) []Transition {
+	config := gen.config
+
+	maxNumSuccessors := config.MaxNumSuccessors
+	if maxNumSuccessors > numStates {
+		maxNumSuccessors = numStates
+	}
+	if maxNumSuccessors < 2 {
+		return transitions
+	}
+
+	numTransitions := len(transitions)
+	for i := 0; i < numTransitions; i++ {
+		if r.Float64() >= config.BranchingProbability {
+			continue
+		}
+		transition := transitions[i]
+		numTargets := r.Intn(maxNumSuccessors-1) + 1
+		for _, state := range r.Perm(numStates) {
+			if numTargets == 0 {
+				break
+			}
+			if state != transition.To {
+				transitions = append(transitions, Transition{
+					From:  transition.From,
+					To:    state,
+					Label: transition.Label,
+				})
+				numTargets--
+			}
+		}
+	}
+	return transitions
+}
+
+/*
+Draw the probabilities of transitions, the transitions from a state
+with a label getting a random distribution over their targets.
+Silent transitions are not probabilistic, each of them is taken
+with probability 1.
+*/
+func (gen *Generator) drawProbabilities(r *rand.Rand, transitions []Transition) {
+	// transitions from each state with each label
+	var keys [][2]int
+	groups := make(map[[2]int][]int)
+	for i, transition := range transitions {
+		if transition.Label == TauLabel {
+			transitions[i].Probability = 1
+			continue
+		}
+		key := [2]int{transition.From, transition.Label}
+		if groups[key] == nil {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	for _, key := range keys {
+		group := groups[key]
+		distribution := gen.drawDistribution(r, len(group))
+		for i, transition := range group {
+			transitions[transition].Probability = distribution[i]
+		}
+	}
+}
+
+/*
+Draw a distribution with size non-zero probabilities: for rational
+probabilities, multiples of 1/ProbabilityGranularity obtained by
+cutting [0, 1] at random distinct points, for float probabilities,
+normalised random weights
+*/
+func (gen *Generator) drawDistribution(r *rand.Rand, size int) []float64 {
+	config := gen.config
+	distribution := make([]float64, size)
+
+	if config.ProbabilityType == ProbabilityRational {
+		granularity := config.ProbabilityGranularity
+		cuts := make(map[int]bool)
+		points := []int{0, granularity}
+		for len(cuts) < size-1 {
+			cut := r.Intn(granularity-1) + 1
+			if !cuts[cut] {
+				cuts[cut] = true
+				points = append(points, cut)
+			}
+		}
+		sort.Ints(points)
+		for i := range distribution {
+			distribution[i] = float64(points[i+1]-points[i]) / float64(granularity)
+		}
+		return distribution
+	}
+
+	total := 0.0
+	for i := range distribution {
+		// weights in ]0, 1]
+		distribution[i] = 1 - r.Float64()
+		total += distribution[i]
+	}
+	remaining := 1.0
+	for i := 0; i < size-1; i++ {
+		distribution[i] /= total
+		remaining -= distribution[i]
+	}
+	distribution[size-1] = remaining
+	return distribution
+}
+
+/*
+Draw the cost of the transitions with a label from a state,
+according to CostDistribution
+*/
+func (gen *Generator) drawCost(r *rand.Rand, label int) float64 {
+	config := gen.config
+	switch config.CostDistribution {
+	case CostConstant:
+		return config.MinCost
+	case CostUniform:
+		return gen.drawCostValue(r)
+	case CostPerLabel:
+		// the costs of the labels added when repairing
+		// the connectivity are drawn when they are needed
+		cost, found := gen.labelCosts[label]
+		if !found {
+			cost = gen.drawCostValue(r)
+			gen.labelCosts[label] = cost
+		}
+		return cost
+	}
+	return 0
+}
+
+/*
+Draw a cost uniformly between MinCost and MaxCost
+*/
+func (gen *Generator) drawCostValue(r *rand.Rand) float64 {
+	config := gen.config
+	if config.CostType == CostInt {
+		return config.MinCost + float64(r.Intn(int(config.MaxCost-config.MinCost)+1))
+	}
+	return config.MinCost + r.Float64()*(config.MaxCost-config.MinCost)
+}
+
+/*
+Add silent transitions to an automaton, between distinct states,
+so that they are a SilentTransitionRatio part of its transitions
+*/
+func (gen *Generator) addSilentTransitions(r *rand.Rand, transitions []Transition, numStates int) []Transition {
+	ratio := gen.config.SilentTransitionRatio
+
+	numSilent := int(math.Round(ratio * float64(len(transitions)) / (1 - ratio)))
+	if numSilent > numStates*(numStates-1) {
+		numSilent = numStates * (numStates - 1)
+	}
+
+	used := make(map[[2]int]bool)
+	for len(used) < numSilent {
+		from := r.Intn(numStates)
+		to := r.Intn(numStates - 1)
+		if to >= from {
+			to++
+		}
+		if used[[2]int{from, to}] {
+			continue
+		}
+		used[[2]int{from, to}] = true
+		transitions = append(transitions, Transition{
+			From:  from,
+			To:    to,
+			Label: TauLabel,
+		})
+	}
+	return transitions
+}
+
+/*
+Generate an automaton with the source of randomness r, which
+is only used by this automaton. The projection of the planted plan on the
+labels of the automaton, if not nil, is first made executable from
+the initial state and leads to a goal state, then transitions
+are added at random.
+*/
+func (gen *Generator) genAutomaton(r *rand.Rand, labels []int, projection []int) Automaton {
+
+	config := gen.config
+
+	// number of states
+	numStates := r.Intn(config.MaxNumStatesPerAutomaton-config.MinNumStatesPerAutomaton+1) + config.MinNumStatesPerAutomaton
+	log.Print("Number of states: ", numStates)
+
+	// number of goal states
+	numGoalStates := r.Intn(config.MaxNumGoalStatesPerAutomaton-config.MinNumGoalStatesPerAutomaton+1) + config.MinNumGoalStatesPerAutomaton
+	if numGoalStates > numStates {
+		numGoalStates = numStates
+	}
+	log.Print("Number of goal states: ", numGoalStates)
+
+	// set of goal states
+	allStates := make([]int, numStates)
+	for i := 0; i < numStates; i++ {
+		allStates[i] = i
+	}
+	r.Shuffle(numStates, func(i, j int) {
+		allStates[i], allStates[j] = allStates[j], allStates[i]
+	})
+	goalStates := make([]int, numGoalStates)
+	copy(goalStates, allStates[:numGoalStates])
+
+	// set of transitions
+	transitions := make([]Transition, 0)
+	nextStatePos := 1
+	allStatesReached := numStates <= 1
+	if allStatesReached {
+		nextStatePos = 0
+	}
+	usedLabels := make([]bool, len(labels))
+	numLabelsUsed := 0
+	allLabelsUsed := false
+	labelsUsedPerState := make([][]bool, numStates)
+	for i := 0; i < numStates; i++ {
+		labelsUsedPerState[i] = make([]bool, len(labels))
+	}
+	numLabelsUsedPerState := make([]int, numStates)
+	blockedStates := make([]bool, numStates)
+	numBlockedStates := 0
+	minNumTransitions := config.MinNumTransitionsPerAutomaton
+	if minNumTransitions > numStates*len(labels) {
+		minNumTransitions = numStates * len(labels)
+	}
+	enoughTransitions := config.MinNumTransitionsPerAutomaton == 0
+	minNumTransitionsPerState := config.MinNumTransitionsPerState
+	if minNumTransitionsPerState > len(labels) {
+		minNumTransitionsPerState = len(labels)
+	}
+	enoughTransitionsStates := make([]bool, numStates)
+	numEnoughTransitionsStates := 0
+	enoughTransitionsPerState := config.MinNumTransitionsPerState == 0
+
+	// give the next state to reach
+	takeNextState := func() int {
+		var nextState int
+		if allStatesReached {
+			nextState = allStates[nextStatePos]
+		} else {
+			nextState = nextStatePos
+		}
+		nextStatePos++
+		if nextStatePos >= numStates {
+			r.Shuffle(numStates, func(i, j int) {
+				allStates[i], allStates[j] = allStates[j], allStates[i]
+			})
+			nextStatePos = 0
+			allStatesReached = true
+		}
+		return nextState
+	}
+
+	// add a transition from state with the label at position
+	// labelPos to nextState, this label must not be used yet
+	// from state
+	addTransition := func(state, labelPos, nextState int) {
+		labelsUsedPerState[state][labelPos] = true
+		numLabelsUsedPerState[state]++
+		if numLabelsUsedPerState[state] >= len(labels) {
+			blockedStates[state] = true
+			numBlockedStates++
+		}
+		if !enoughTransitionsStates[state] && numLabelsUsedPerState[state] >= minNumTransitionsPerState {
+			enoughTransitionsStates[state] = true
+			numEnoughTransitionsStates++
+			enoughTransitionsPerState = numEnoughTransitionsStates >= numStates
+		}
+		if !usedLabels[labelPos] {
+			usedLabels[labelPos] = true
+			numLabelsUsed++
+			allLabelsUsed = numLabelsUsed >= len(labels)
+		}
+		// add a transition between the two states
+		transitions = append(transitions, Transition{
+			From:  state,
+			To:    nextState,
+			Label: labels[labelPos],
+		})
+		// count this transition
+		enoughTransitions = len(transitions) >= minNumTransitions
+	}
+
+	// walk along the projection of the planted plan, reusing the
+	// transitions already added to keep the automaton deterministic
+	if projection != nil {
+		labelPositions := make(map[int]int)
+		for i, label := range labels {
+			labelPositions[label] = i
+		}
+		targets := make(map[[2]int]int)
+		current := 0
+		for _, label := range projection {
+			labelPos := labelPositions[label]
+			if nextState, found := targets[[2]int{current, labelPos}]; found {
+				current = nextState
+				continue
+			}
+			// a new state or an already reached one, making sure
+			// that some reached state is not blocked
+			var nextState int
+			if allStatesReached {
+				nextState = r.Intn(numStates)
+			} else {
+				blocking := numLabelsUsedPerState[current]+1 >= len(labels)
+				if r.Intn(nextStatePos+1) == nextStatePos ||
+					(blocking && nextStatePos-numBlockedStates-1 <= 0) {
+					nextState = takeNextState()
+				} else {
+					nextState = r.Intn(nextStatePos)
+				}
+			}
+			addTransition(current, labelPos, nextState)
+			targets[[2]int{current, labelPos}] = nextState
+			current = nextState
+		}
+		// the end of the walk must be a goal state
+		isGoal := false
+		for _, state := range goalStates {
+			isGoal = isGoal || state == current
+		}
+		if !isGoal {
+			goalStates[r.Intn(numGoalStates)] = current
+		}
+		log.Print("Planted plan of length ", len(projection), " ending in state ", current)
+	}
+
+	for !allStatesReached || !allLabelsUsed ||
+		!enoughTransitions || !enoughTransitionsPerState {
+		// choose a reachable state
+		var state int
+		var stateNum int
+		if allStatesReached {
+			stateNum = r.Intn(numStates - numBlockedStates)
+		} else {
+			stateNum = r.Intn(nextStatePos - numBlockedStates)
+		}
+		stateCount := 0
+		statePos := 0
+		for stateCount <= stateNum {
+			if !blockedStates[statePos] {
+				stateCount++
+				state = statePos
+			}
+			statePos++
+		}
+		// choose a state to reach from it
+		nextState := takeNextState()
+		// choose a label
+		labelNum := r.Intn(len(labels) - numLabelsUsedPerState[state])
+		labelCount := 0
+		labelPos := 0
+		for labelCount <= labelNum {
+			if !labelsUsedPerState[state][labelPos] {
+				labelCount++
+			}
+			labelPos++
+		}
+		addTransition(state, labelPos-1, nextState)
+	}
+	// additional successors for nondeterministic
+	// and probabilistic automata
+	if config.Determinism != DeterminismDeterministic {
+		transitions = gen.branch(r, transitions, numStates)
+	}
+
+	// silent transitions
+	if config.SilentTransitionRatio > 0 {
+		transitions = gen.addSilentTransitions(r, transitions, numStates)
+	}
+
+	// distributions over the successors
+	if config.Determinism == DeterminismProbabilistic {
+		gen.drawProbabilities(r, transitions)
+	}
+
+	// costs, one for each state and label
+	if config.CostDistribution != CostNone {
+		costs := make(map[[2]int]float64)
+		for i, transition := range transitions {
+			key := [2]int{transition.From, transition.Label}
+			cost, found := costs[key]
+			if !found {
+				cost = gen.drawCost(r, transition.Label)
+				costs[key] = cost
+			}
+			transitions[i].Cost = cost
+		}
+	}
+	log.Print("Number of transitions: ", len(transitions))
+
+	return Automaton{
+		NumStates:   numStates,
+		Labels:      labels,
+		GoalStates:  goalStates,
+		Transitions: transitions,
+	}
+}
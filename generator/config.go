@@ -0,0 +1,839 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+Characteristics of the generated automata, see README.md
+for the meaning of each field.
+*/
+type Configuration struct {
+	MinNumStatesPerAutomaton        int
+	MaxNumStatesPerAutomaton        int
+	MinNumGoalStatesPerAutomaton    int
+	MaxNumGoalStatesPerAutomaton    int
+	MinNumLabelsPerAutomaton        int
+	MaxNumLabelsPerAutomaton        int
+	MinNumPrivateLabelsPerAutomaton int
+	MaxNumPrivateLabelsPerAutomaton int
+	MinNumTransitionsPerState       int
+	MinNumTransitionsPerAutomaton   int
+	NumAutomata                     int
+	Seed                            int64
+	Connectivity                    string
+	Topology                        string
+	TopologyBranching               int
+	TopologyGridWidth               int
+	TopologyEdgeProbability         float64
+	TopologyAttachment              int
+	TopologyFile                    string
+	MinNumAutomataPerSharedLabel    int
+	MaxNumAutomataPerSharedLabel    int
+	SharedLabelArityDistribution    string
+	Solvability                     string
+	MaxNumAttempts                  int
+	ProductStateBudget              int
+	PlantedPlanLength               int
+	Determinism                     string
+	MaxNumSuccessors                int
+	BranchingProbability            float64
+	SilentTransitionRatio           float64
+	CostDistribution                string
+	CostType                        string
+	MinCost                         float64
+	MaxCost                         float64
+	ProbabilityType                 string
+	ProbabilityGranularity          int
+}
+
+// required solvability of the generated networks
+const (
+	SolvabilityAny        = "any"
+	SolvabilitySolvable   = "solvable"
+	SolvabilityUnsolvable = "unsolvable"
+)
+
+// kinds of generated automata
+const (
+	DeterminismDeterministic    = "deterministic"
+	DeterminismNondeterministic = "nondeterministic"
+	DeterminismProbabilistic    = "probabilistic"
+)
+
+// types of the probabilities of probabilistic automata
+const (
+	ProbabilityRational = "rational"
+	ProbabilityFloat    = "float"
+)
+
+// distributions of the costs of the transitions
+const (
+	CostNone     = "none"
+	CostConstant = "constant"
+	CostUniform  = "uniform"
+	CostPerLabel = "per-label"
+)
+
+// types of the costs of the transitions
+const (
+	CostInt  = "int"
+	CostReal = "real"
+)
+
+// prefix of the environment variables setting configuration fields
+const environmentPrefix = "NOAG_"
+
+// ways of handling disconnected interaction graphs
+const (
+	ConnectivityRepair = "repair"
+	ConnectivityFail   = "fail"
+)
+
+/*
+Read a configuration from a json file, a warning is logged
+for each field which is not a field of Configuration.
+No correction is applied at this point, see New.
+*/
+func ReadConfigurationFile(file string) (Configuration, error) {
+	return readConfigurationFile(file, false)
+}
+
+/*
+Read a configuration from a json file, fields which are
+not fields of Configuration (typos for example) are errors.
+*/
+func ReadConfigurationFileStrict(file string) (Configuration, error) {
+	return readConfigurationFile(file, true)
+}
+
+func readConfigurationFile(file string, strict bool) (Configuration, error) {
+	log.Print("Reading configuration file ", file)
+
+	var config Configuration
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return config, fmt.Errorf("cannot open configuration file %s: %w", file, err)
+	}
+
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return config, fmt.Errorf("cannot parse configuration file %s: %w", file, err)
+	}
+
+	// unknown fields, which are ignored by json.Unmarshal
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return config, fmt.Errorf("cannot parse configuration file %s: %w", file, err)
+	}
+	unknown := make([]string, 0)
+	v := reflect.ValueOf(config)
+	for field := range fields {
+		if !v.FieldByNameFunc(func(name string) bool { return strings.EqualFold(name, field) }).IsValid() {
+			unknown = append(unknown, field)
+		}
+	}
+	sort.Strings(unknown)
+	if strict && len(unknown) > 0 {
+		return config, fmt.Errorf("unknown fields in configuration file %s: %s", file, strings.Join(unknown, ", "))
+	}
+	for _, field := range unknown {
+		log.Print("Warning, unknown field ", field, " in configuration file ", file, ", ignored")
+	}
+
+	return config, nil
+}
+
+/*
+Set the field of a configuration given by its name (case is not
+significant) to the value given as a string. No correction is
+applied at this point, see New.
+*/
+func (config *Configuration) Set(field, value string) error {
+	f := config.field(field)
+	if !f.IsValid() {
+		return fmt.Errorf("unknown configuration field %s", field)
+	}
+
+	switch f.Kind() {
+	case reflect.Int, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value %q for configuration field %s: expecting an integer", value, field)
+		}
+		f.SetInt(i)
+	case reflect.Float64:
+		x, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value %q for configuration field %s: expecting a number", value, field)
+		}
+		f.SetFloat(x)
+	case reflect.String:
+		f.SetString(value)
+	default:
+		return fmt.Errorf("configuration field %s cannot be set", field)
+	}
+	return nil
+}
+
+/*
+Field of a configuration given by its name (case is not
+significant), the zero Value if there is no such field
+*/
+func (config *Configuration) field(field string) reflect.Value {
+	return reflect.ValueOf(config).Elem().FieldByNameFunc(func(name string) bool {
+		return strings.EqualFold(name, field)
+	})
+}
+
+/*
+Set the fields of a configuration from the environment variables
+named after them with the prefix NOAG_ (for example NOAG_NumAutomata
+or NOAG_NUMAUTOMATA), see Set. A warning is logged for each variable
+which is not named after a field of Configuration.
+*/
+func (config *Configuration) SetFromEnvironment() error {
+	return config.setFromEnvironment(false)
+}
+
+/*
+Set the fields of a configuration from the environment variables,
+as SetFromEnvironment, variables with the prefix NOAG_ which are
+not named after a field of Configuration being errors.
+*/
+func (config *Configuration) SetFromEnvironmentStrict() error {
+	return config.setFromEnvironment(true)
+}
+
+func (config *Configuration) setFromEnvironment(strict bool) error {
+	for _, variable := range os.Environ() {
+		if !strings.HasPrefix(variable, environmentPrefix) {
+			continue
+		}
+		pos := strings.Index(variable, "=")
+		field := variable[len(environmentPrefix):pos]
+		if !strict && !config.field(field).IsValid() {
+			log.Print("Warning, unknown field ", field, " in environment variable ", variable[:pos], ", ignored")
+			continue
+		}
+		log.Print("Setting ", field, " from environment variable ", variable[:pos])
+		if err := config.Set(field, variable[pos+1:]); err != nil {
+			return fmt.Errorf("environment variable %s: %w", variable[:pos], err)
+		}
+	}
+	return nil
+}
+
+/*
+Set the parameters left to their zero value
+to their default value
+*/
+func (config *Configuration) setDefaults() {
+	if config.Connectivity == "" {
+		config.Connectivity = ConnectivityRepair
+	}
+
+	if config.Topology == "" {
+		config.Topology = TopologyRandom
+	}
+	if config.Topology == TopologyTree && config.TopologyBranching == 0 {
+		config.TopologyBranching = 2
+	}
+	// edge probability close to the connectivity
+	// threshold of random graphs
+	if config.Topology == TopologyErdosRenyi && config.TopologyEdgeProbability == 0 {
+		config.TopologyEdgeProbability = edgeProbability(config.NumAutomata)
+	}
+	if config.Topology == TopologyBarabasiAlbert && config.TopologyAttachment == 0 {
+		config.TopologyAttachment = 1
+	}
+
+	if config.SharedLabelArityDistribution == "" {
+		config.SharedLabelArityDistribution = ArityUniform
+	}
+
+	if config.Solvability == "" {
+		config.Solvability = SolvabilityAny
+	}
+	if config.MaxNumAttempts == 0 {
+		config.MaxNumAttempts = 100
+	}
+	if config.ProductStateBudget == 0 {
+		config.ProductStateBudget = 1000000
+	}
+
+	if config.Determinism == "" {
+		config.Determinism = DeterminismDeterministic
+	}
+	if config.Determinism == DeterminismNondeterministic || config.Determinism == DeterminismProbabilistic {
+		if config.MaxNumSuccessors == 0 {
+			config.MaxNumSuccessors = 2
+		}
+		if config.BranchingProbability == 0 {
+			config.BranchingProbability = 0.5
+		}
+	}
+	if config.Determinism == DeterminismProbabilistic {
+		if config.ProbabilityType == "" {
+			config.ProbabilityType = ProbabilityRational
+		}
+		if config.ProbabilityType != ProbabilityFloat && config.ProbabilityGranularity == 0 {
+			config.ProbabilityGranularity = 10
+		}
+	}
+
+	if config.CostDistribution == "" {
+		config.CostDistribution = CostNone
+	}
+	if config.CostType == "" {
+		config.CostType = CostInt
+	}
+	// unit costs
+	if (config.CostDistribution == CostConstant || config.CostDistribution == CostUniform ||
+		config.CostDistribution == CostPerLabel) && config.MinCost == 0 && config.MaxCost == 0 {
+		config.MinCost = 1
+		config.MaxCost = 1
+	}
+}
+
+/*
+Default edge probability of Erdős–Rényi interaction graphs
+with numAutomata nodes
+*/
+func edgeProbability(numAutomata int) float64 {
+	if numAutomata > 1 {
+		return math.Min(1, 2*math.Log(float64(numAutomata))/float64(numAutomata))
+	}
+	return 1.0
+}
+
+/*
+Set the parameters left to their zero value to their default
+value, correct the parameters which are inconsistent and return
+the constraints which were violated. Unless strict is set, a
+warning is logged for each correction and each constraint is
+checked once the previous ones are corrected. If strict is set,
+all the constraints are checked on the parameters as given.
+*/
+func (config *Configuration) correct(strict bool) []string {
+	config.setDefaults()
+
+	// parameters the constraints are checked on
+	checked := config
+	if strict {
+		original := *config
+		checked = &original
+	}
+
+	violations := make([]string, 0)
+	fix := func(correction interface{}, constraint ...interface{}) {
+		violation := fmt.Sprint(constraint...)
+		if !strict {
+			log.Print("Warning, ", violation, ", automatically set to ", correction)
+		}
+		violations = append(violations, violation)
+	}
+
+	// at least one state per automaton
+	if checked.MinNumStatesPerAutomaton < 1 {
+		fix(1,
+			"MinNumStatesPerAutomaton (", checked.MinNumStatesPerAutomaton,
+			") should be at least 1",
+		)
+		config.MinNumStatesPerAutomaton = 1
+	}
+
+	// max number of states greater than min number of states
+	if checked.MaxNumStatesPerAutomaton < checked.MinNumStatesPerAutomaton {
+		fix(checked.MinNumStatesPerAutomaton,
+			"MaxNumStatesPerAutomaton (", checked.MaxNumStatesPerAutomaton,
+			") should be at least equal to MinNumStatesPerAutomaton (", checked.MinNumStatesPerAutomaton, ")",
+		)
+		config.MaxNumStatesPerAutomaton = checked.MinNumStatesPerAutomaton
+	}
+
+	// at least one goal state per automaton
+	if checked.MinNumGoalStatesPerAutomaton < 1 {
+		fix(1,
+			"MinNumGoalStatesPerAutomaton (", checked.MinNumGoalStatesPerAutomaton,
+			") should be at least 1",
+		)
+		config.MinNumGoalStatesPerAutomaton = 1
+	}
+
+	// min number of goal states smaller than max number of states
+	if checked.MinNumGoalStatesPerAutomaton > checked.MaxNumStatesPerAutomaton {
+		fix(checked.MaxNumStatesPerAutomaton,
+			"MinNumGoalStatesPerAutomaton (", checked.MinNumGoalStatesPerAutomaton,
+			") should be smaller or equal than MaxNumStatesPerAutomaton (", checked.MaxNumStatesPerAutomaton, ")",
+		)
+		config.MinNumGoalStatesPerAutomaton = checked.MaxNumStatesPerAutomaton
+	}
+
+	// max number of goal states greater than min number of goal states
+	if checked.MaxNumGoalStatesPerAutomaton < checked.MinNumGoalStatesPerAutomaton {
+		fix(checked.MinNumGoalStatesPerAutomaton,
+			"MaxNumGoalStatesPerAutomaton (", checked.MaxNumGoalStatesPerAutomaton,
+			") should be at least equal to MinNumGoalStatesPerAutomaton (", checked.MinNumGoalStatesPerAutomaton, ")",
+		)
+		config.MaxNumGoalStatesPerAutomaton = checked.MinNumGoalStatesPerAutomaton
+	}
+
+	// max number of goal states smaller than max number of states
+	if checked.MaxNumGoalStatesPerAutomaton > checked.MaxNumStatesPerAutomaton {
+		fix(checked.MaxNumStatesPerAutomaton,
+			"MaxNumGoalStatesPerAutomaton (", checked.MaxNumGoalStatesPerAutomaton,
+			") should be smaller or equal than MaxNumStatesPerAutomaton (", checked.MaxNumStatesPerAutomaton, ")",
+		)
+		config.MaxNumGoalStatesPerAutomaton = checked.MaxNumStatesPerAutomaton
+	}
+
+	// at least one label per automaton
+	if checked.MinNumLabelsPerAutomaton < 1 {
+		fix(1,
+			"MinNumLabelsPerAutomaton (", checked.MinNumLabelsPerAutomaton,
+			") should be at least 1",
+		)
+		config.MinNumLabelsPerAutomaton = 1
+	}
+
+	// max number of labels greater than min number of labels
+	if checked.MaxNumLabelsPerAutomaton < checked.MinNumLabelsPerAutomaton {
+		fix(checked.MinNumLabelsPerAutomaton,
+			"MaxNumLabelsPerAutomaton (", checked.MaxNumLabelsPerAutomaton,
+			") should be at least equal to MinNumLabelsPerAutomaton (", checked.MinNumLabelsPerAutomaton, ")",
+		)
+		config.MaxNumLabelsPerAutomaton = checked.MinNumLabelsPerAutomaton
+	}
+
+	// at least zero private label per automaton
+	if checked.MinNumPrivateLabelsPerAutomaton < 0 {
+		fix(0,
+			"MinNumPrivateLabelsPerAutomaton (", checked.MinNumPrivateLabelsPerAutomaton,
+			") should not be negative",
+		)
+		config.MinNumPrivateLabelsPerAutomaton = 0
+	}
+
+	// min number of private labels smaller than max number of labels - 1
+	if checked.MinNumPrivateLabelsPerAutomaton > checked.MaxNumLabelsPerAutomaton-1 {
+		fix(checked.MaxNumLabelsPerAutomaton-1,
+			"MinNumPrivateLabelsPerAutomaton (", checked.MinNumPrivateLabelsPerAutomaton,
+			") should be strictly smaller than MaxNumLabelsPerAutomaton (", checked.MaxNumLabelsPerAutomaton, ")",
+		)
+		config.MinNumPrivateLabelsPerAutomaton = checked.MaxNumLabelsPerAutomaton - 1
+	}
+
+	// max number of private labels greater than min number of private labels
+	if checked.MaxNumPrivateLabelsPerAutomaton < checked.MinNumPrivateLabelsPerAutomaton {
+		fix(checked.MinNumPrivateLabelsPerAutomaton,
+			"MaxNumPrivateLabelsPerAutomaton (", checked.MaxNumPrivateLabelsPerAutomaton,
+			") should be at least equal to MinNumPrivateLabelsPerAutomaton (", checked.MinNumPrivateLabelsPerAutomaton, ")",
+		)
+		config.MaxNumPrivateLabelsPerAutomaton = checked.MinNumPrivateLabelsPerAutomaton
+	}
+
+	// max number of private labels smaller than max number of labels - 1
+	if checked.MaxNumPrivateLabelsPerAutomaton > checked.MaxNumLabelsPerAutomaton-1 {
+		fix(checked.MaxNumLabelsPerAutomaton-1,
+			"MaxNumPrivateLabelsPerAutomaton (", checked.MaxNumPrivateLabelsPerAutomaton,
+			") should be strictly smaller than MaxNumLabelsPerAutomaton (", checked.MaxNumLabelsPerAutomaton, ")",
+		)
+		config.MaxNumPrivateLabelsPerAutomaton = checked.MaxNumLabelsPerAutomaton - 1
+	}
+
+	// at least zero transition per state
+	if checked.MinNumTransitionsPerState < 0 {
+		fix(0,
+			"MinNumTransitionsPerState (", checked.MinNumTransitionsPerState,
+			") should not be negative",
+		)
+		config.MinNumTransitionsPerState = 0
+	}
+
+	// no more transitions per state than labels
+	if checked.MinNumTransitionsPerState > checked.MaxNumLabelsPerAutomaton {
+		fix(checked.MaxNumLabelsPerAutomaton,
+			"MinNumTransitionsPerState (", checked.MinNumTransitionsPerState,
+			") should be smaller or equal than MaxNumLabelsPerAutomaton (", checked.MaxNumLabelsPerAutomaton, ")",
+		)
+		config.MinNumTransitionsPerState = checked.MaxNumLabelsPerAutomaton
+	}
+
+	// at least one transition per automaton
+	if checked.MinNumTransitionsPerAutomaton < 1 {
+		fix(1,
+			"MinNumTransitionsPerAutomaton (", checked.MinNumTransitionsPerAutomaton,
+			") should be at least 1",
+		)
+		config.MinNumTransitionsPerAutomaton = 1
+	}
+
+	// no more transitions per automaton than labels * states
+	if checked.MinNumTransitionsPerAutomaton > checked.MaxNumLabelsPerAutomaton*checked.MaxNumStatesPerAutomaton {
+		fix(checked.MaxNumLabelsPerAutomaton*checked.MaxNumStatesPerAutomaton,
+			"MinNumTransitionsPerAutomaton (", checked.MinNumTransitionsPerAutomaton,
+			") should be smaller or equal than MaxNumLabelsPerAutomaton * MaxNumStatesPerAutomaton (",
+			checked.MaxNumLabelsPerAutomaton, " * ", checked.MaxNumStatesPerAutomaton, ")",
+		)
+		config.MinNumTransitionsPerAutomaton = checked.MaxNumLabelsPerAutomaton * checked.MaxNumStatesPerAutomaton
+	}
+
+	// at least (transitions per state * states) transitions in an automaton
+	if checked.MinNumTransitionsPerAutomaton < checked.MinNumTransitionsPerState*checked.MinNumStatesPerAutomaton {
+		fix(checked.MinNumTransitionsPerState*checked.MinNumStatesPerAutomaton,
+			"MinNumTransitionsPerAutomaton (", checked.MinNumTransitionsPerAutomaton,
+			") should be greater or equal than MinNumTransitionsPerState * MinNumStatesPerAutomaton (",
+			checked.MinNumTransitionsPerState, " * ", checked.MinNumStatesPerAutomaton, ")",
+		)
+		config.MinNumTransitionsPerAutomaton = checked.MinNumTransitionsPerState * checked.MinNumStatesPerAutomaton
+	}
+
+	// at least one automaton
+	if checked.NumAutomata < 1 {
+		fix(1,
+			"NumAutomata (", checked.NumAutomata,
+			") should be at least 1",
+		)
+		config.NumAutomata = 1
+	}
+
+	// known way of handling disconnected interaction graphs
+	if checked.Connectivity != ConnectivityRepair && checked.Connectivity != ConnectivityFail {
+		fix(ConnectivityRepair,
+			"Connectivity (", checked.Connectivity,
+			") should be ", ConnectivityRepair, " or ", ConnectivityFail,
+		)
+		config.Connectivity = ConnectivityRepair
+	}
+
+	// known topology
+	knownTopology := false
+	for _, topology := range topologies {
+		knownTopology = knownTopology || checked.Topology == topology
+	}
+	if !knownTopology {
+		fix(TopologyRandom,
+			"Topology (", checked.Topology,
+			") should be one of ", topologies,
+		)
+		config.Topology = TopologyRandom
+	}
+
+	// a file must be given for reading the topology from
+	if checked.Topology == TopologyFromFile && checked.TopologyFile == "" {
+		fix(TopologyRandom,
+			"Topology is ", TopologyFromFile, " but no TopologyFile is given",
+		)
+		config.Topology = TopologyRandom
+	}
+
+	// at least one child per node in a tree
+	if checked.Topology == TopologyTree && checked.TopologyBranching < 1 {
+		fix(2,
+			"TopologyBranching (", checked.TopologyBranching,
+			") should be at least 1",
+		)
+		config.TopologyBranching = 2
+	}
+
+	// positive width for grids, 0 stands for a square grid
+	if checked.Topology == TopologyGrid && checked.TopologyGridWidth < 0 {
+		fix("0 (square grid)",
+			"TopologyGridWidth (", checked.TopologyGridWidth,
+			") should not be negative",
+		)
+		config.TopologyGridWidth = 0
+	}
+
+	// edge probability in ]0, 1]
+	if checked.Topology == TopologyErdosRenyi &&
+		(checked.TopologyEdgeProbability <= 0 || checked.TopologyEdgeProbability > 1) {
+		p := edgeProbability(checked.NumAutomata)
+		fix(p,
+			"TopologyEdgeProbability (", checked.TopologyEdgeProbability,
+			") should be in ]0, 1]",
+		)
+		config.TopologyEdgeProbability = p
+	}
+
+	// at least one edge per new automaton with preferential attachment
+	if checked.Topology == TopologyBarabasiAlbert && checked.TopologyAttachment < 1 {
+		fix(1,
+			"TopologyAttachment (", checked.TopologyAttachment,
+			") should be at least 1",
+		)
+		config.TopologyAttachment = 1
+	}
+
+	// no negative bounds on the number of automata per shared label,
+	// 0 stands for no bound
+	if checked.MinNumAutomataPerSharedLabel < 0 {
+		fix("0 (no minimum)",
+			"MinNumAutomataPerSharedLabel (", checked.MinNumAutomataPerSharedLabel,
+			") should not be negative",
+		)
+		config.MinNumAutomataPerSharedLabel = 0
+	}
+	if checked.MaxNumAutomataPerSharedLabel < 0 {
+		fix("0 (no maximum)",
+			"MaxNumAutomataPerSharedLabel (", checked.MaxNumAutomataPerSharedLabel,
+			") should not be negative",
+		)
+		config.MaxNumAutomataPerSharedLabel = 0
+	}
+
+	// a shared label is shared by at least two automata
+	if checked.MinNumAutomataPerSharedLabel == 1 {
+		fix(2,
+			"MinNumAutomataPerSharedLabel (", checked.MinNumAutomataPerSharedLabel,
+			") should be at least 2",
+		)
+		config.MinNumAutomataPerSharedLabel = 2
+	}
+	if checked.MaxNumAutomataPerSharedLabel == 1 {
+		fix(2,
+			"MaxNumAutomataPerSharedLabel (", checked.MaxNumAutomataPerSharedLabel,
+			") should be at least 2",
+		)
+		config.MaxNumAutomataPerSharedLabel = 2
+	}
+
+	// no more automata per shared label than automata
+	if checked.MinNumAutomataPerSharedLabel > checked.NumAutomata && checked.NumAutomata >= 2 {
+		fix(checked.NumAutomata,
+			"MinNumAutomataPerSharedLabel (", checked.MinNumAutomataPerSharedLabel,
+			") should be smaller or equal than NumAutomata (", checked.NumAutomata, ")",
+		)
+		config.MinNumAutomataPerSharedLabel = checked.NumAutomata
+	}
+
+	// max number of automata per shared label greater than min number
+	if checked.MaxNumAutomataPerSharedLabel != 0 &&
+		checked.MaxNumAutomataPerSharedLabel < checked.MinNumAutomataPerSharedLabel {
+		fix(checked.MinNumAutomataPerSharedLabel,
+			"MaxNumAutomataPerSharedLabel (", checked.MaxNumAutomataPerSharedLabel,
+			") should be at least equal to MinNumAutomataPerSharedLabel (", checked.MinNumAutomataPerSharedLabel, ")",
+		)
+		config.MaxNumAutomataPerSharedLabel = checked.MinNumAutomataPerSharedLabel
+	}
+
+	// known distribution of the number of automata per shared label
+	if checked.SharedLabelArityDistribution != ArityUniform && checked.SharedLabelArityDistribution != ArityGeometric {
+		fix(ArityUniform,
+			"SharedLabelArityDistribution (", checked.SharedLabelArityDistribution,
+			") should be ", ArityUniform, " or ", ArityGeometric,
+		)
+		config.SharedLabelArityDistribution = ArityUniform
+	}
+
+	// known solvability
+	if checked.Solvability != SolvabilityAny &&
+		checked.Solvability != SolvabilitySolvable &&
+		checked.Solvability != SolvabilityUnsolvable {
+		fix(SolvabilityAny,
+			"Solvability (", checked.Solvability,
+			") should be ", SolvabilityAny, ", ", SolvabilitySolvable, " or ", SolvabilityUnsolvable,
+		)
+		config.Solvability = SolvabilityAny
+	}
+
+	// at least one attempt
+	if checked.MaxNumAttempts < 1 {
+		fix(100,
+			"MaxNumAttempts (", checked.MaxNumAttempts,
+			") should be at least 1",
+		)
+		config.MaxNumAttempts = 100
+	}
+
+	// at least one product state explored
+	if checked.ProductStateBudget < 1 {
+		fix(1000000,
+			"ProductStateBudget (", checked.ProductStateBudget,
+			") should be at least 1",
+		)
+		config.ProductStateBudget = 1000000
+	}
+
+	// no negative plan length, 0 stands for no planted plan
+	if checked.PlantedPlanLength < 0 {
+		fix("0 (no planted plan)",
+			"PlantedPlanLength (", checked.PlantedPlanLength,
+			") should not be negative",
+		)
+		config.PlantedPlanLength = 0
+	}
+
+	// a planted plan makes the network solvable
+	if checked.PlantedPlanLength > 0 && checked.Solvability == SolvabilityUnsolvable {
+		fix(0,
+			"PlantedPlanLength (", checked.PlantedPlanLength,
+			") should be 0 for unsolvable networks",
+		)
+		config.PlantedPlanLength = 0
+	}
+
+	// known kind of automata
+	if checked.Determinism != DeterminismDeterministic && checked.Determinism != DeterminismNondeterministic &&
+		checked.Determinism != DeterminismProbabilistic {
+		fix(DeterminismDeterministic,
+			"Determinism (", checked.Determinism,
+			") should be ", DeterminismDeterministic, ", ", DeterminismNondeterministic,
+			" or ", DeterminismProbabilistic,
+		)
+		config.Determinism = DeterminismDeterministic
+	}
+
+	if checked.Determinism != DeterminismDeterministic {
+		// at least two successors for branching
+		if checked.MaxNumSuccessors < 2 {
+			fix(2,
+				"MaxNumSuccessors (", checked.MaxNumSuccessors,
+				") should be at least 2",
+			)
+			config.MaxNumSuccessors = 2
+		}
+
+		// branching probability
+		if checked.BranchingProbability <= 0 || checked.BranchingProbability > 1 {
+			fix(0.5,
+				"BranchingProbability (", checked.BranchingProbability,
+				") should be in ]0, 1]",
+			)
+			config.BranchingProbability = 0.5
+		}
+	}
+
+	if checked.Determinism == DeterminismProbabilistic {
+		// known type of probabilities
+		if checked.ProbabilityType != ProbabilityRational && checked.ProbabilityType != ProbabilityFloat {
+			fix(ProbabilityRational,
+				"ProbabilityType (", checked.ProbabilityType,
+				") should be ", ProbabilityRational, " or ", ProbabilityFloat,
+			)
+			config.ProbabilityType = ProbabilityRational
+		}
+
+		// rational probabilities are multiples of 1/ProbabilityGranularity,
+		// each successor needs at least one of them
+		if checked.ProbabilityType == ProbabilityRational &&
+			checked.ProbabilityGranularity < checked.MaxNumSuccessors {
+			fix(checked.MaxNumSuccessors,
+				"ProbabilityGranularity (", checked.ProbabilityGranularity,
+				") should be at least equal to MaxNumSuccessors (", checked.MaxNumSuccessors, ")",
+			)
+			config.ProbabilityGranularity = checked.MaxNumSuccessors
+		}
+	}
+
+	// part of silent transitions
+	if checked.SilentTransitionRatio < 0 || checked.SilentTransitionRatio >= 1 {
+		fix(0,
+			"SilentTransitionRatio (", checked.SilentTransitionRatio,
+			") should be in [0, 1[",
+		)
+		config.SilentTransitionRatio = 0
+	}
+
+	// known distribution of costs
+	if checked.CostDistribution != CostNone && checked.CostDistribution != CostConstant &&
+		checked.CostDistribution != CostUniform && checked.CostDistribution != CostPerLabel {
+		fix(CostNone,
+			"CostDistribution (", checked.CostDistribution,
+			") should be ", CostNone, ", ", CostConstant, ", ", CostUniform, " or ", CostPerLabel,
+		)
+		config.CostDistribution = CostNone
+	}
+
+	// known type of costs
+	if checked.CostType != CostInt && checked.CostType != CostReal {
+		fix(CostInt,
+			"CostType (", checked.CostType,
+			") should be ", CostInt, " or ", CostReal,
+		)
+		config.CostType = CostInt
+	}
+
+	if checked.CostDistribution != CostNone {
+		// non-negative costs
+		if checked.MinCost < 0 {
+			fix(0,
+				"MinCost (", checked.MinCost,
+				") should not be negative",
+			)
+			config.MinCost = 0
+		}
+
+		// integer bounds for integer costs
+		if checked.CostType == CostInt && checked.MinCost != math.Ceil(checked.MinCost) {
+			fix(math.Ceil(checked.MinCost),
+				"MinCost (", checked.MinCost,
+				") should be an integer for ", CostInt, " costs",
+			)
+			config.MinCost = math.Ceil(checked.MinCost)
+		}
+		if checked.CostType == CostInt && checked.MaxCost != math.Floor(checked.MaxCost) {
+			fix(math.Floor(checked.MaxCost),
+				"MaxCost (", checked.MaxCost,
+				") should be an integer for ", CostInt, " costs",
+			)
+			config.MaxCost = math.Floor(checked.MaxCost)
+		}
+
+		// max cost greater than min cost
+		if checked.CostDistribution != CostConstant && checked.MaxCost < checked.MinCost {
+			fix(checked.MinCost,
+				"MaxCost (", checked.MaxCost,
+				") should be at least equal to MinCost (", checked.MinCost, ")",
+			)
+			config.MaxCost = checked.MinCost
+		}
+
+		// constant costs are all MinCost
+		if config.CostDistribution == CostConstant {
+			config.MaxCost = config.MinCost
+		}
+	}
+
+	return violations
+}
+
+/*
+Error listing all the constraints violated by a configuration
+*/
+type ConfigurationError struct {
+	Violations []string
+}
+
+func (err ConfigurationError) Error() string {
+	return "inconsistent configuration: " + strings.Join(err.Violations, "; ")
+}
@@ -0,0 +1,52 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"testing"
+)
+
+/*
+An environment variable with the prefix NOAG_ which is not named
+after a field is ignored, unless in strict mode
+*/
+func TestSetFromEnvironmentUnknownField(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	os.Setenv("NOAG_Foo", "1")
+	defer os.Unsetenv("NOAG_Foo")
+	os.Setenv("NOAG_NumAutomata", "7")
+	defer os.Unsetenv("NOAG_NumAutomata")
+
+	var config Configuration
+	if err := config.SetFromEnvironment(); err != nil {
+		t.Fatalf("unknown field in the environment: %v", err)
+	}
+	if config.NumAutomata != 7 {
+		t.Errorf("NumAutomata is %d instead of 7", config.NumAutomata)
+	}
+
+	if err := config.SetFromEnvironmentStrict(); err == nil {
+		t.Error("no error for an unknown field in the environment in strict mode")
+	}
+}
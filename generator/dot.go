@@ -0,0 +1,148 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+Write a network in the DOT language of graphviz: one digraph
+per automaton followed by an undirected graph representing the
+interactions between automata (two automata are linked when they
+share at least one label, the edge being labelled by these labels).
+*/
+func (n Network) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for i, a := range n.Automata {
+		a.writeDOT(bw, i, n)
+	}
+	n.writeInteractionDOT(bw)
+	return bw.Flush()
+}
+
+/*
+Write the automaton numbered id of the network n as a DOT
+digraph, the initial state is pointed by an arrow without origin
+and the goal states are drawn as double circles.
+*/
+func (a Automaton) writeDOT(w *bufio.Writer, id int, n Network) {
+	fmt.Fprintf(w, "digraph %s {\n", strconv.Quote(a.nameOf(id)))
+	fmt.Fprintf(w, "\trankdir=LR;\n")
+
+	// initial state
+	fmt.Fprintf(w, "\t%s [shape=point];\n", strconv.Quote("_init"))
+
+	// states
+	isGoal := make([]bool, a.NumStates)
+	for _, state := range a.GoalStates {
+		isGoal[state] = true
+	}
+	for i := 0; i < a.NumStates; i++ {
+		shape := "circle"
+		if isGoal[i] {
+			shape = "doublecircle"
+		}
+		fmt.Fprintf(w, "\t%s [shape=%s];\n", strconv.Quote(a.stateNameOf(i)), shape)
+	}
+	fmt.Fprintf(w, "\t%s -> %s;\n", strconv.Quote("_init"), strconv.Quote(a.stateNameOf(0)))
+
+	// transitions
+	for _, transition := range a.Transitions {
+		// silent transitions are dashed
+		style := ""
+		if transition.Label == TauLabel {
+			style = ", style=dashed"
+		}
+		// costs follow the labels, then probabilities
+		label := n.LabelName(transition.Label)
+		if n.Weighted {
+			label += "/" + strconv.FormatFloat(transition.Cost, 'g', -1, 64)
+		}
+		if n.Probabilistic && transition.Label != TauLabel {
+			label += " (" + n.probabilityString(transition.Probability) + ")"
+		}
+		fmt.Fprintf(w, "\t%s -> %s [label=%s%s];\n",
+			strconv.Quote(a.stateNameOf(transition.From)),
+			strconv.Quote(a.stateNameOf(transition.To)),
+			strconv.Quote(label),
+			style,
+		)
+	}
+
+	fmt.Fprintf(w, "}\n")
+}
+
+/*
+Write the interaction graph of the network as an undirected DOT graph
+*/
+func (n Network) writeInteractionDOT(w *bufio.Writer) {
+	fmt.Fprintf(w, "graph %s {\n", strconv.Quote("interactions"))
+
+	ig := n.InteractionGraph()
+	for i, a := range n.Automata {
+		fmt.Fprintf(w, "\t%s;\n", strconv.Quote(a.nameOf(i)))
+	}
+
+	// labels shared by each pair of automata
+	type pair struct {
+		first  int
+		second int
+	}
+	labelsPerPair := make(map[pair][]int)
+	for label, automata := range ig.SharedLabels {
+		for i := 0; i < len(automata); i++ {
+			for j := i + 1; j < len(automata); j++ {
+				p := pair{first: automata[i], second: automata[j]}
+				labelsPerPair[p] = append(labelsPerPair[p], label)
+			}
+		}
+	}
+	pairs := make([]pair, 0, len(labelsPerPair))
+	for p := range labelsPerPair {
+		pairs = append(pairs, p)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].first != pairs[j].first {
+			return pairs[i].first < pairs[j].first
+		}
+		return pairs[i].second < pairs[j].second
+	})
+
+	for _, p := range pairs {
+		labels := labelsPerPair[p]
+		sort.Ints(labels)
+		names := make([]string, len(labels))
+		for i, label := range labels {
+			names[i] = n.LabelName(label)
+		}
+		fmt.Fprintf(w, "\t%s -- %s [label=%s];\n",
+			strconv.Quote(n.Automata[p.first].nameOf(p.first)),
+			strconv.Quote(n.Automata[p.second].nameOf(p.second)),
+			strconv.Quote(strings.Join(names, ",")),
+		)
+	}
+
+	fmt.Fprintf(w, "}\n")
+}
@@ -16,20 +16,22 @@ You should have received a copy of the GNU General Public License
 along with this program.  If not, see <https://www.gnu.org/licenses/>.
 */
 
-package main
-
-// characteristics of the generated automata
-var config Configuration
+/*
+Package generator provides random generation of networks of automata.
 
-// default config file
-const (
-	configFile = "conf.json"
-	outputFile = "out.json"
-)
+A Generator is built from a Configuration and a source of randomness,
+the same configuration and source always give the same Network.
+*/
+package generator
 
 // default names of things
 const (
 	automatonName = "A"
 	stateName     = "s"
 	actionName    = "a"
+	tauName       = "tau"
 )
+
+// reserved label of the silent transitions, which is
+// not an input symbol of the automata and never synchronised
+const TauLabel = -1
@@ -0,0 +1,406 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+/*
+A network of automata, with the configuration used
+for generating it.
+*/
+type Network struct {
+	Configuration Configuration
+	Automata      []Automaton
+	// number of networks generated before this one was
+	// accepted, including itself
+	Attempts int
+	// planted plan, a sequence of labels leading to a global
+	// goal state, nil if no plan was planted
+	Plan []int
+	// optional names of the labels, the labels
+	// are named after their number otherwise
+	LabelNames map[int]string
+	// the transitions have costs
+	Weighted bool
+	// the transitions have probabilities, the transitions
+	// from a state with a label form a distribution
+	Probabilistic bool
+	// denominator of rational probabilities,
+	// 0 for float probabilities
+	ProbabilityDenominator int
+	// the network was read from a file without metadata
+	// block, its json representation has none either
+	NoMetadata bool
+	// the network was generated with a source of randomness
+	// which was not seeded with the Seed of its configuration,
+	// so it cannot be regenerated from its configuration
+	ExternalSource bool
+}
+
+/*
+Name of a label of the network
+*/
+func (n Network) LabelName(label int) string {
+	if label == TauLabel {
+		return tauName
+	}
+	if name, found := n.LabelNames[label]; found {
+		return name
+	}
+	return fmt.Sprint(actionName, label)
+}
+
+/*
+Generator of networks of automata.
+*/
+type Generator struct {
+	config Configuration
+	rand   *rand.Rand
+	// inconsistencies are errors instead of being corrected
+	strict bool
+	// number of goroutines building the automata
+	numWorkers int
+	// costs of the labels for per-label costs
+	labelCosts map[int]float64
+	// the source of randomness was given instead
+	// of being seeded with the Seed of the configuration
+	externalSource bool
+}
+
+/*
+Build a generator from a configuration and a source of randomness.
+The configuration is corrected if some of its parameters are
+inconsistent. If source is nil, a source seeded with the Seed
+of the configuration is used. Otherwise the generated networks
+cannot be regenerated from their metadata, which records it.
+*/
+func New(config Configuration, source rand.Source) *Generator {
+	config.correct(false)
+	externalSource := source != nil
+	if !externalSource {
+		source = rand.NewSource(config.Seed)
+	}
+	return &Generator{
+		config:         config,
+		rand:           rand.New(source),
+		externalSource: externalSource,
+	}
+}
+
+/*
+Build a generator from a configuration and a source of randomness,
+as New, but an error listing all the violated constraints is returned
+if some parameters of the configuration are inconsistent. During the
+generation, inconsistencies between the configuration and the
+interaction graph file are also errors.
+*/
+func NewStrict(config Configuration, source rand.Source) (*Generator, error) {
+	if violations := config.correct(true); len(violations) > 0 {
+		return nil, ConfigurationError{Violations: violations}
+	}
+	gen := New(config, source)
+	gen.strict = true
+	return gen, nil
+}
+
+/*
+Set the number of goroutines building the automata concurrently,
+1 (the default) for building them one after the other. The
+generated networks do not depend on the number of workers.
+*/
+func (gen *Generator) SetNumWorkers(numWorkers int) {
+	gen.numWorkers = numWorkers
+}
+
+/*
+The configuration actually used by the generator,
+after corrections.
+*/
+func (gen *Generator) Configuration() Configuration {
+	return gen.config
+}
+
+/*
+Generate a network of automata. When the configuration asks for
+solvable or unsolvable networks, networks are generated until one
+has the required solvability, up to MaxNumAttempts times.
+*/
+func (gen *Generator) Generate() (Network, error) {
+	config := gen.config
+
+	for attempt := 1; ; attempt++ {
+		g, err := gen.generate()
+		if err != nil || config.Solvability == SolvabilityAny ||
+			(config.Solvability == SolvabilitySolvable && g.Plan != nil) {
+			g.Attempts = attempt
+			return g, err
+		}
+
+		log.Print("Checking the solvability of the network (attempt ", attempt, ")")
+		result := g.Product(config.ProductStateBudget)
+		switch {
+		case !result.Complete:
+			log.Print("Solvability unknown, state budget exhausted after ", result.ExploredStates, " product states")
+		case config.Solvability == SolvabilitySolvable && result.Reachable,
+			config.Solvability == SolvabilityUnsolvable && !result.Reachable:
+			log.Print("Network with the required solvability found after ", attempt, " attempts")
+			g.Attempts = attempt
+			return g, nil
+		default:
+			log.Print("Network rejected, global goal reachable: ", result.Reachable)
+		}
+
+		if attempt >= config.MaxNumAttempts {
+			g.Attempts = attempt
+			return g, fmt.Errorf("no %s network found in %d attempts", config.Solvability, attempt)
+		}
+	}
+}
+
+/*
+Generate a network of automata, without constraint on solvability.
+The labels of all the automata are allocated first, then each
+automaton is built with its own source of randomness, seeded from
+the one of the generator, so that the automata can be built
+concurrently without changing the result.
+*/
+func (gen *Generator) generate() (Network, error) {
+	allLabels, names, err := gen.allocateLabels()
+	if err != nil {
+		return Network{}, err
+	}
+	config := gen.config
+
+	log.Print("Starting generation of ", config.NumAutomata, " automata")
+
+	var g Network
+	g.Configuration = config
+	g.Automata = make([]Automaton, config.NumAutomata)
+	g.ExternalSource = gen.externalSource
+	g.Weighted = config.CostDistribution != CostNone
+	g.Probabilistic = config.Determinism == DeterminismProbabilistic
+	if g.Probabilistic && config.ProbabilityType == ProbabilityRational {
+		g.ProbabilityDenominator = config.ProbabilityGranularity
+	}
+
+	// planted plan
+	if config.PlantedPlanLength > 0 {
+		g.Plan = gen.drawPlan(allLabels)
+		log.Print("Planted plan: ", g.Plan)
+	}
+
+	// seeds of the automata
+	seeds := make([]int64, config.NumAutomata)
+	for i := range seeds {
+		seeds[i] = gen.rand.Int63()
+	}
+
+	// costs of the labels, drawn before building the automata
+	// as they are shared by the automata
+	if config.CostDistribution == CostPerLabel {
+		gen.labelCosts = map[int]float64{TauLabel: gen.drawCostValue(gen.rand)}
+		for _, label := range sortedLabels(allLabels) {
+			gen.labelCosts[label] = gen.drawCostValue(gen.rand)
+		}
+	}
+
+	gen.genAutomata(g.Automata, allLabels, seeds, g.Plan)
+	if names != nil {
+		for i := range g.Automata {
+			g.Automata[i].Name = names[i]
+		}
+	}
+
+	// check that the interaction graph is connected
+	components := g.InteractionGraph().Components()
+	if len(components) > 1 {
+		switch {
+		case config.Connectivity == ConnectivityFail:
+			return g, fmt.Errorf("the interaction graph has %d connected components", len(components))
+		case config.Topology == TopologyFromFile:
+			// the interaction graph must stay the one of the file
+			if gen.strict {
+				return g, fmt.Errorf(
+					"the interaction graph of %s has %d connected components",
+					config.TopologyFile, len(components),
+				)
+			}
+			log.Print(
+				"Warning: the interaction graph of ", config.TopologyFile,
+				" has ", len(components), " connected components, not repaired",
+			)
+		default:
+			log.Print("Warning: the interaction graph has ", len(components), " connected components, repairing it")
+			if err := gen.connect(&g, components); err != nil {
+				return g, err
+			}
+		}
+	}
+
+	log.Print("Generation complete")
+	return g, nil
+}
+
+/*
+Allocate the labels of all the automata according to the topology,
+with the names of the automata if they are given by the topology
+*/
+func (gen *Generator) allocateLabels() (allLabels [][]int, names []string, err error) {
+	config := gen.config
+
+	switch config.Topology {
+	case TopologyRandom:
+		var rl randomLabels
+		allLabels = make([][]int, config.NumAutomata)
+		for i := range allLabels {
+			allLabels[i] = gen.nextRandomLabels(&rl)
+		}
+		// share the public labels between enough automata
+		if gen.arityBounded() {
+			err = gen.padSharedLabels(allLabels, &rl)
+		}
+	case TopologyFromFile:
+		var edges [][2]int
+		names, edges, err = ReadInteractionGraphFile(config.TopologyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(names) != config.NumAutomata {
+			if gen.strict {
+				return nil, nil, fmt.Errorf(
+					"NumAutomata (%d) differs from the number of automata in %s (%d)",
+					config.NumAutomata, config.TopologyFile, len(names),
+				)
+			}
+			log.Print(
+				"Warning: NumAutomata (",
+				config.NumAutomata,
+				") differs from the number of automata in ", config.TopologyFile,
+				", automatically set to ", len(names),
+			)
+			gen.config.NumAutomata = len(names)
+		}
+		// the labels are shared exactly along the edges of the file
+		allLabels, err = gen.labelsFromEdges(len(names), edges, true)
+	default:
+		allLabels, err = gen.labelsFromEdges(config.NumAutomata, gen.topologyEdges(config.NumAutomata), false)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return allLabels, names, nil
+}
+
+/*
+Build the automata given their labels and the seeds of their
+sources of randomness, with NumWorkers goroutines
+*/
+func (gen *Generator) genAutomata(automata []Automaton, allLabels [][]int, seeds []int64, plan []int) {
+	build := func(i int) {
+		log.Print("Starting generation of automaton ", automatonName, i)
+		r := rand.New(rand.NewSource(seeds[i]))
+		automata[i] = gen.genAutomaton(r, allLabels[i], project(plan, allLabels[i]))
+		log.Print("Labels: ", automata[i].Labels)
+		log.Print("Automaton ", automatonName, i, " generated")
+	}
+
+	if gen.numWorkers <= 1 {
+		for i := range automata {
+			build(i)
+		}
+		return
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < gen.numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				build(i)
+			}
+		}()
+	}
+	for i := range automata {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+/*
+Draw a plan of PlantedPlanLength labels, uniformly
+among the labels of the automata
+*/
+func (gen *Generator) drawPlan(allLabels [][]int) []int {
+	r := gen.rand
+
+	labels := sortedLabels(allLabels)
+	plan := make([]int, gen.config.PlantedPlanLength)
+	for i := range plan {
+		plan[i] = labels[r.Intn(len(labels))]
+	}
+	return plan
+}
+
+/*
+Labels of a set of automata, in increasing order
+*/
+func sortedLabels(allLabels [][]int) []int {
+	seen := make(map[int]bool)
+	labels := make([]int, 0)
+	for _, automatonLabels := range allLabels {
+		for _, label := range automatonLabels {
+			if !seen[label] {
+				seen[label] = true
+				labels = append(labels, label)
+			}
+		}
+	}
+	sort.Ints(labels)
+	return labels
+}
+
+/*
+Projection of a plan on a set of labels, nil if there is no plan
+*/
+func project(plan []int, labels []int) []int {
+	if plan == nil {
+		return nil
+	}
+	has := make(map[int]bool)
+	for _, label := range labels {
+		has[label] = true
+	}
+	projection := make([]int, 0)
+	for _, label := range plan {
+		if has[label] {
+			projection = append(projection, label)
+		}
+	}
+	return projection
+}
@@ -0,0 +1,494 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+/*
+Read an interaction graph from a file, either in the DOT language
+(files with extension .dot or .gv, or starting with a graph or digraph
+keyword) or as an edge list. In an edge list each line gives the names
+of two interacting automata separated by spaces or a comma, a line with
+a single name declares an automaton without interactions, and lines
+starting with # are comments.
+The names of the automata are returned in order of first appearance
+and the edges refer to the positions of the automata in this order.
+The direction of the edges is not relevant, self-loops and duplicated
+edges are ignored.
+*/
+func ReadInteractionGraphFile(file string) (names []string, edges [][2]int, err error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot open interaction graph file %s: %w", file, err)
+	}
+
+	ext := strings.ToLower(filepath.Ext(file))
+	var b graphBuilder
+	b.ids = make(map[string]int)
+	b.edges = make(map[[2]int]bool)
+	if ext == ".dot" || ext == ".gv" || looksLikeDOT(data) {
+		err = b.parseDOT(data)
+	} else {
+		err = b.parseEdgeList(data)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse interaction graph file %s: %w", file, err)
+	}
+	return b.names, b.edgeList, nil
+}
+
+/*
+Incremental construction of an interaction graph
+from names of automata
+*/
+type graphBuilder struct {
+	names    []string
+	ids      map[string]int
+	edges    map[[2]int]bool
+	edgeList [][2]int
+}
+
+func (b *graphBuilder) node(name string) int {
+	id, found := b.ids[name]
+	if !found {
+		id = len(b.names)
+		b.ids[name] = id
+		b.names = append(b.names, name)
+	}
+	return id
+}
+
+func (b *graphBuilder) edge(first, second string) {
+	i, j := b.node(first), b.node(second)
+	if i == j {
+		return
+	}
+	if i > j {
+		i, j = j, i
+	}
+	if !b.edges[[2]int{i, j}] {
+		b.edges[[2]int{i, j}] = true
+		b.edgeList = append(b.edgeList, [2]int{i, j})
+	}
+}
+
+func (b *graphBuilder) parseEdgeList(data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.FieldsFunc(text, func(c rune) bool {
+			return c == ',' || unicode.IsSpace(c)
+		})
+		switch len(fields) {
+		case 1:
+			b.node(fields[0])
+		case 2:
+			b.edge(fields[0], fields[1])
+		default:
+			return fmt.Errorf("line %d: expecting one or two names, found %d", line, len(fields))
+		}
+	}
+	return scanner.Err()
+}
+
+/*
+Check if some data starts with the header of a DOT graph
+*/
+func looksLikeDOT(data []byte) bool {
+	l := dotLexer{data: data, line: 1}
+	tok, err := l.next()
+	if err != nil {
+		return false
+	}
+	keyword := strings.ToLower(tok.text)
+	return tok.kind == dotID && (keyword == "graph" || keyword == "digraph" || keyword == "strict")
+}
+
+/*
+Parse a graph in the DOT language, only the nodes and edges are
+kept, attributes are ignored and subgraphs are flattened.
+*/
+func (b *graphBuilder) parseDOT(data []byte) error {
+	p := dotParser{lexer: dotLexer{data: data, line: 1}}
+	if err := p.advance(); err != nil {
+		return err
+	}
+
+	// header
+	if p.isKeyword("strict") {
+		if err := p.advance(); err != nil {
+			return err
+		}
+	}
+	if !p.isKeyword("graph") && !p.isKeyword("digraph") {
+		return p.errorf("expecting graph or digraph")
+	}
+	if err := p.advance(); err != nil {
+		return err
+	}
+	if p.tok.kind == dotID {
+		if err := p.advance(); err != nil {
+			return err
+		}
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	if err := p.statements(b); err != nil {
+		return err
+	}
+	if p.tok.kind != dotEOF {
+		return p.errorf("unexpected %q after the end of the graph", p.tok.text)
+	}
+	return nil
+}
+
+type dotParser struct {
+	lexer dotLexer
+	tok   dotToken
+}
+
+func (p *dotParser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *dotParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.tok.line, fmt.Sprintf(format, args...))
+}
+
+func (p *dotParser) isKeyword(keyword string) bool {
+	return p.tok.kind == dotID && !p.tok.quoted && strings.EqualFold(p.tok.text, keyword)
+}
+
+func (p *dotParser) isPunct(punct string) bool {
+	return p.tok.kind == dotPunct && p.tok.text == punct
+}
+
+func (p *dotParser) expect(punct string) error {
+	if !p.isPunct(punct) {
+		return p.errorf("expecting %q, found %q", punct, p.tok.text)
+	}
+	return p.advance()
+}
+
+/*
+Parse statements up to the closing brace of the current block
+*/
+func (p *dotParser) statements(b *graphBuilder) error {
+	for !p.isPunct("}") {
+		if p.tok.kind == dotEOF {
+			return p.errorf("missing closing brace")
+		}
+		if err := p.statement(b); err != nil {
+			return err
+		}
+		if p.isPunct(";") {
+			if err := p.advance(); err != nil {
+				return err
+			}
+		}
+	}
+	return p.advance()
+}
+
+func (p *dotParser) statement(b *graphBuilder) error {
+	// attribute statements
+	if p.isKeyword("graph") || p.isKeyword("node") || p.isKeyword("edge") {
+		if err := p.advance(); err != nil {
+			return err
+		}
+		return p.attributes()
+	}
+
+	// subgraphs are flattened
+	if p.isKeyword("subgraph") || p.isPunct("{") {
+		if p.isKeyword("subgraph") {
+			if err := p.advance(); err != nil {
+				return err
+			}
+			if p.tok.kind == dotID {
+				if err := p.advance(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := p.expect("{"); err != nil {
+			return err
+		}
+		if err := p.statements(b); err != nil {
+			return err
+		}
+		if p.tok.kind == dotEdgeOp {
+			return p.errorf("edges from subgraphs are not supported")
+		}
+		return nil
+	}
+
+	if p.tok.kind != dotID {
+		return p.errorf("unexpected %q", p.tok.text)
+	}
+	name, err := p.nodeID()
+	if err != nil {
+		return err
+	}
+
+	// graph attribute
+	if p.isPunct("=") {
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if p.tok.kind != dotID {
+			return p.errorf("expecting a value after =")
+		}
+		return p.advance()
+	}
+
+	// node or edge statement
+	b.node(name)
+	for p.tok.kind == dotEdgeOp {
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if p.isKeyword("subgraph") || p.isPunct("{") {
+			return p.errorf("edges to subgraphs are not supported")
+		}
+		if p.tok.kind != dotID {
+			return p.errorf("expecting a node after an edge operator")
+		}
+		next, err := p.nodeID()
+		if err != nil {
+			return err
+		}
+		b.edge(name, next)
+		name = next
+	}
+	return p.attributes()
+}
+
+/*
+Parse a node identifier, dropping its port if any
+*/
+func (p *dotParser) nodeID() (string, error) {
+	name := p.tok.text
+	if err := p.advance(); err != nil {
+		return "", err
+	}
+	for p.isPunct(":") {
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+		if p.tok.kind != dotID {
+			return "", p.errorf("expecting a port after :")
+		}
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+	}
+	return name, nil
+}
+
+/*
+Skip attribute lists, if any
+*/
+func (p *dotParser) attributes() error {
+	for p.isPunct("[") {
+		for !p.isPunct("]") {
+			if p.tok.kind == dotEOF {
+				return p.errorf("missing closing bracket")
+			}
+			if err := p.advance(); err != nil {
+				return err
+			}
+		}
+		if err := p.advance(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// kinds of DOT tokens
+const (
+	dotEOF = iota
+	dotID
+	dotEdgeOp
+	dotPunct
+)
+
+type dotToken struct {
+	kind   int
+	text   string
+	quoted bool
+	line   int
+}
+
+type dotLexer struct {
+	data []byte
+	pos  int
+	line int
+}
+
+func (l *dotLexer) next() (dotToken, error) {
+	l.skipSpacesAndComments()
+	tok := dotToken{line: l.line}
+	if l.pos >= len(l.data) {
+		tok.kind = dotEOF
+		return tok, nil
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '-' && l.pos+1 < len(l.data) && (l.data[l.pos+1] == '-' || l.data[l.pos+1] == '>'):
+		tok.kind = dotEdgeOp
+		tok.text = string(l.data[l.pos : l.pos+2])
+		l.pos += 2
+	case c == '"':
+		tok.kind = dotID
+		tok.quoted = true
+		var sb strings.Builder
+		l.pos++
+		for {
+			if l.pos >= len(l.data) {
+				return tok, fmt.Errorf("line %d: unterminated string", tok.line)
+			}
+			c = l.data[l.pos]
+			if c == '"' {
+				l.pos++
+				break
+			}
+			if c == '\\' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '"' {
+				c = '"'
+				l.pos++
+			}
+			if c == '\n' {
+				l.line++
+			}
+			sb.WriteByte(c)
+			l.pos++
+		}
+		tok.text = sb.String()
+	case c == '<':
+		// html strings, nested angle brackets
+		tok.kind = dotID
+		tok.quoted = true
+		start := l.pos
+		depth := 0
+		for {
+			if l.pos >= len(l.data) {
+				return tok, fmt.Errorf("line %d: unterminated html string", tok.line)
+			}
+			switch l.data[l.pos] {
+			case '<':
+				depth++
+			case '>':
+				depth--
+			case '\n':
+				l.line++
+			}
+			l.pos++
+			if depth == 0 {
+				break
+			}
+		}
+		tok.text = string(l.data[start+1 : l.pos-1])
+	case isDOTLetter(c):
+		tok.kind = dotID
+		start := l.pos
+		for l.pos < len(l.data) && (isDOTLetter(l.data[l.pos]) || isDigit(l.data[l.pos])) {
+			l.pos++
+		}
+		tok.text = string(l.data[start:l.pos])
+	case isDigit(c) || c == '.' || c == '-':
+		// numerals
+		tok.kind = dotID
+		start := l.pos
+		l.pos++
+		for l.pos < len(l.data) && (isDigit(l.data[l.pos]) || l.data[l.pos] == '.') {
+			l.pos++
+		}
+		tok.text = string(l.data[start:l.pos])
+	case strings.IndexByte("{}[];=,:", c) >= 0:
+		tok.kind = dotPunct
+		tok.text = string(c)
+		l.pos++
+	default:
+		return tok, fmt.Errorf("line %d: unexpected character %q", tok.line, c)
+	}
+	return tok, nil
+}
+
+func isDOTLetter(c byte) bool {
+	return c == '_' || c >= 0x80 || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func (l *dotLexer) skipSpacesAndComments() {
+	atLineStart := l.pos == 0 || l.data[l.pos-1] == '\n'
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case c == '\n':
+			l.line++
+			l.pos++
+			atLineStart = true
+		case c == ' ' || c == '\t' || c == '\r':
+			l.pos++
+		case c == '#' && atLineStart:
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' {
+				l.pos++
+			}
+		case c == '/' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '/':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' {
+				l.pos++
+			}
+		case c == '/' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '*':
+			l.pos += 2
+			for l.pos < len(l.data) && !(l.data[l.pos] == '*' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '/') {
+				if l.data[l.pos] == '\n' {
+					l.line++
+				}
+				l.pos++
+			}
+			l.pos += 2
+		default:
+			return
+		}
+	}
+}
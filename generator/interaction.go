@@ -0,0 +1,323 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"fmt"
+	"log"
+	"sort"
+)
+
+/*
+Interaction graph of a network: the automata are the vertices
+and each label shared by several automata is a hyperedge between
+these automata. Automata are identified by their position in the
+network.
+*/
+type InteractionGraph struct {
+	NumAutomata int
+	Names       []string
+	// automata using each label shared by at least two automata
+	SharedLabels map[int][]int
+	// neighbours of each automaton, in increasing order
+	Adjacency [][]int
+}
+
+/*
+Statistics on degrees, for example the ones of the vertices of an
+interaction graph: Distribution[d] is the number of automata with
+d neighbours.
+*/
+type DegreeStatistics struct {
+	Min          int
+	Max          int
+	Mean         float64
+	Distribution []int
+}
+
+/*
+Build the interaction graph of a network
+*/
+func (n Network) InteractionGraph() InteractionGraph {
+	ig := InteractionGraph{
+		NumAutomata:  len(n.Automata),
+		SharedLabels: make(map[int][]int),
+		Adjacency:    make([][]int, len(n.Automata)),
+		Names:        make([]string, len(n.Automata)),
+	}
+
+	automataPerLabel := make(map[int][]int)
+	for i, a := range n.Automata {
+		ig.Names[i] = a.nameOf(i)
+		for _, label := range a.Labels {
+			automataPerLabel[label] = append(automataPerLabel[label], i)
+		}
+	}
+
+	neighbours := make([]map[int]bool, len(n.Automata))
+	for i := range neighbours {
+		neighbours[i] = make(map[int]bool)
+	}
+	for label, automata := range automataPerLabel {
+		if len(automata) < 2 {
+			continue
+		}
+		ig.SharedLabels[label] = automata
+		for _, i := range automata {
+			for _, j := range automata {
+				if i != j {
+					neighbours[i][j] = true
+				}
+			}
+		}
+	}
+
+	for i := range neighbours {
+		ig.Adjacency[i] = make([]int, 0, len(neighbours[i]))
+		for j := range neighbours[i] {
+			ig.Adjacency[i] = append(ig.Adjacency[i], j)
+		}
+		sort.Ints(ig.Adjacency[i])
+	}
+
+	return ig
+}
+
+/*
+Connected components of the interaction graph, computed with
+a union-find on the hyperedges. Components are ordered by their
+smallest automaton and each component is sorted.
+*/
+func (ig InteractionGraph) Components() [][]int {
+	uf := newUnionFind(ig.NumAutomata)
+	for _, automata := range ig.SharedLabels {
+		for _, i := range automata[1:] {
+			uf.union(automata[0], i)
+		}
+	}
+
+	components := make([][]int, 0)
+	componentOf := make(map[int]int)
+	for i := 0; i < ig.NumAutomata; i++ {
+		root := uf.find(i)
+		c, found := componentOf[root]
+		if !found {
+			c = len(components)
+			componentOf[root] = c
+			components = append(components, make([]int, 0))
+		}
+		components[c] = append(components[c], i)
+	}
+
+	return components
+}
+
+/*
+Check if the interaction graph has only one connected component
+*/
+func (ig InteractionGraph) Connected() bool {
+	return len(ig.Components()) <= 1
+}
+
+/*
+Compute statistics on the degrees of the automata
+*/
+func (ig InteractionGraph) DegreeStatistics() DegreeStatistics {
+	degrees := make([]int, ig.NumAutomata)
+	for i, neighbours := range ig.Adjacency {
+		degrees[i] = len(neighbours)
+	}
+	return degreeStatistics(degrees)
+}
+
+/*
+Compute statistics on a list of degrees
+*/
+func degreeStatistics(degrees []int) DegreeStatistics {
+	var stats DegreeStatistics
+	if len(degrees) == 0 {
+		return stats
+	}
+	stats.Min = degrees[0]
+	sum := 0
+	for _, degree := range degrees {
+		sum += degree
+		if degree < stats.Min {
+			stats.Min = degree
+		}
+		if degree > stats.Max {
+			stats.Max = degree
+		}
+	}
+	stats.Mean = float64(sum) / float64(len(degrees))
+	stats.Distribution = make([]int, stats.Max+1)
+	for _, degree := range degrees {
+		stats.Distribution[degree]++
+	}
+	return stats
+}
+
+/*
+Make the interaction graph of a network connected by linking
+its components in a chain: for two consecutive components, a
+new label is added to a random automaton of each of them, with
+one transition using this label in each of these automata. The
+automata with less than MaxNumLabelsPerAutomaton labels are
+chosen whenever possible, exceeding the bound being an error
+in strict mode.
+*/
+func (gen *Generator) connect(g *Network, components [][]int) error {
+	r := gen.rand
+	maxLabels := gen.config.MaxNumLabelsPerAutomaton
+	numBeyond := 0
+
+	choose := func(component []int) int {
+		withRoom := make([]int, 0, len(component))
+		for _, i := range component {
+			if len(g.Automata[i].Labels) < maxLabels {
+				withRoom = append(withRoom, i)
+			}
+		}
+		if len(withRoom) == 0 {
+			numBeyond++
+			withRoom = component
+		}
+		return withRoom[r.Intn(len(withRoom))]
+	}
+
+	lastLabel := 0
+	for _, a := range g.Automata {
+		for _, label := range a.Labels {
+			if label > lastLabel {
+				lastLabel = label
+			}
+		}
+	}
+
+	for c := 1; c < len(components); c++ {
+		lastLabel++
+		first := choose(components[c-1])
+		second := choose(components[c])
+		log.Print("Linking automata ", g.Automata[first].nameOf(first), " and ", g.Automata[second].nameOf(second), " with label ", actionName, lastLabel)
+		gen.addLabel(&g.Automata[first], lastLabel)
+		gen.addLabel(&g.Automata[second], lastLabel)
+	}
+
+	if numBeyond > 0 {
+		if gen.strict {
+			return fmt.Errorf(
+				"MaxNumLabelsPerAutomaton (%d) cannot be respected when connecting the interaction graph",
+				maxLabels,
+			)
+		}
+		log.Print(
+			"Warning: ", numBeyond, " labels given to automata beyond MaxNumLabelsPerAutomaton (",
+			maxLabels, ") to connect the interaction graph",
+		)
+	}
+	return nil
+}
+
+/*
+Union-find structure with path compression and union by rank
+*/
+type unionFind struct {
+	parent []int
+	rank   []int
+}
+
+func newUnionFind(size int) unionFind {
+	uf := unionFind{
+		parent: make([]int, size),
+		rank:   make([]int, size),
+	}
+	for i := range uf.parent {
+		uf.parent[i] = i
+	}
+	return uf
+}
+
+func (uf unionFind) find(i int) int {
+	for uf.parent[i] != i {
+		uf.parent[i] = uf.parent[uf.parent[i]]
+		i = uf.parent[i]
+	}
+	return i
+}
+
+func (uf unionFind) union(i, j int) {
+	i = uf.find(i)
+	j = uf.find(j)
+	if i == j {
+		return
+	}
+	if uf.rank[i] < uf.rank[j] {
+		i, j = j, i
+	}
+	uf.parent[j] = i
+	if uf.rank[i] == uf.rank[j] {
+		uf.rank[i]++
+	}
+}
+
+/*
+Json representation of an interaction graph
+*/
+type JSONInteractionGraph struct {
+	Components int                  `json:"components"`
+	Adjacency  []JSONNeighbourhood  `json:"adjacency"`
+	Degrees    JSONDegreeStatistics `json:"degrees"`
+}
+
+type JSONNeighbourhood struct {
+	Automaton  string   `json:"automaton"`
+	Neighbours []string `json:"neighbours"`
+}
+
+type JSONDegreeStatistics struct {
+	Min          int     `json:"min"`
+	Max          int     `json:"max"`
+	Mean         float64 `json:"mean"`
+	Distribution []int   `json:"distribution"`
+}
+
+/*
+Build the json representation of an interaction graph
+*/
+func (ig InteractionGraph) ToJSON() *JSONInteractionGraph {
+	stats := ig.DegreeStatistics()
+	jGraph := JSONInteractionGraph{
+		Components: len(ig.Components()),
+		Adjacency:  make([]JSONNeighbourhood, ig.NumAutomata),
+		Degrees: JSONDegreeStatistics{
+			Min:          stats.Min,
+			Max:          stats.Max,
+			Mean:         stats.Mean,
+			Distribution: stats.Distribution,
+		},
+	}
+	for i, neighbours := range ig.Adjacency {
+		jGraph.Adjacency[i].Automaton = ig.Names[i]
+		jGraph.Adjacency[i].Neighbours = make([]string, len(neighbours))
+		for k, j := range neighbours {
+			jGraph.Adjacency[i].Neighbours[k] = ig.Names[j]
+		}
+	}
+	return &jGraph
+}
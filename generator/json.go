@@ -0,0 +1,353 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+type JSONNetwork struct {
+	Metadata         JSONMetadata          `json:"metadata"`
+	Automata         []JSONAutomaton       `json:"automata"`
+	InteractionGraph *JSONInteractionGraph `json:"interaction_graph,omitempty"`
+	// the network was read from a file with a metadata block,
+	// or built from a network which was
+	hasMetadata bool
+}
+
+/*
+Json representation of a network, the metadata block is omitted
+when the network was read without one and it is empty
+*/
+func (jNetwork JSONNetwork) MarshalJSON() ([]byte, error) {
+	type network JSONNetwork
+	if jNetwork.hasMetadata || !reflect.DeepEqual(jNetwork.Metadata, JSONMetadata{}) {
+		return json.Marshal(network(jNetwork))
+	}
+	return json.Marshal(struct {
+		Automata         []JSONAutomaton       `json:"automata"`
+		InteractionGraph *JSONInteractionGraph `json:"interaction_graph,omitempty"`
+	}{jNetwork.Automata, jNetwork.InteractionGraph})
+}
+
+/*
+Information needed to regenerate a network: the seed used and
+the configuration actually applied (after corrections).
+*/
+type JSONMetadata struct {
+	Seed          int64         `json:"seed"`
+	Configuration Configuration `json:"configuration"`
+	Attempts      int           `json:"attempts,omitempty"`
+	// planted plan, its length is an upper bound
+	// on the length of optimal plans
+	WitnessPlan     []string `json:"witness_plan,omitempty"`
+	PlanLengthBound int      `json:"plan_length_bound,omitempty"`
+	// the seed was not used, the network was generated with
+	// a source of randomness given to the generator
+	ExternalSource bool `json:"external_source,omitempty"`
+}
+
+type JSONAutomaton struct {
+	Name         string          `json:"name"`
+	States       []string        `json:"states"`
+	InputSymbols []string        `json:"input_symbols"`
+	Transitions  JSONTransitions `json:"transitions"`
+	// costs of the transitions of weighted networks
+	Costs        *JSONCosts `json:"costs,omitempty"`
+	InitialState string     `json:"initial_state"`
+	FinalStates  []string   `json:"final_states"`
+	// line of the automaton in the file it was read from
+	line int
+}
+
+/*
+Transitions of an automaton, grouped by origin state,
+in the order in which they are written
+*/
+type JSONTransitions struct {
+	Content []JSONStateTransitions
+}
+
+type JSONStateTransitions struct {
+	From        string
+	Transitions []JSONTransition
+	line        int
+}
+
+type JSONTransition struct {
+	To    string
+	Label string
+	// nil for networks which are not probabilistic, a string
+	// giving a fraction for rational probabilities and a
+	// float64 for float probabilities
+	Probability interface{}
+	line        int
+}
+
+func (jsonTrans JSONTransitions) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	jw := newJSONWriter(&buf)
+	jsonTrans.writeJSON(jw)
+	if err := jw.flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+/*
+Costs of the transitions of an automaton, grouped by origin
+state, with one cost for each label used from the state
+*/
+type JSONCosts struct {
+	Content []JSONStateCosts
+}
+
+type JSONStateCosts struct {
+	From  string
+	Costs []JSONCost
+	line  int
+}
+
+type JSONCost struct {
+	Label string
+	Cost  float64
+	line  int
+}
+
+func (jsonCosts JSONCosts) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	jw := newJSONWriter(&buf)
+	jsonCosts.writeJSON(jw)
+	if err := jw.flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+/*
+Build the json representation of a network, with
+the metadata needed to regenerate it.
+*/
+func (n Network) ToJSON() JSONNetwork {
+	jNetwork := JSONNetwork{
+		Metadata:    n.jsonMetadata(),
+		Automata:    make([]JSONAutomaton, len(n.Automata)),
+		hasMetadata: !n.NoMetadata,
+	}
+	for i, a := range n.Automata {
+		jNetwork.Automata[i] = a.toJSON(i, n)
+	}
+	return jNetwork
+}
+
+func (n Network) jsonMetadata() JSONMetadata {
+	metadata := JSONMetadata{
+		Seed:           n.Configuration.Seed,
+		Configuration:  n.Configuration,
+		Attempts:       n.Attempts,
+		ExternalSource: n.ExternalSource,
+	}
+	if n.Plan != nil {
+		metadata.WitnessPlan = make([]string, len(n.Plan))
+		for i, label := range n.Plan {
+			metadata.WitnessPlan[i] = n.LabelName(label)
+		}
+		metadata.PlanLengthBound = len(n.Plan)
+	}
+	return metadata
+}
+
+/*
+Build the json representation of the automaton
+numbered id in its network. The representation is
+canonical: labels, goal states and transitions are
+sorted by number. The costs (resp. the probabilities,
+as floats) are given if some transition has a non-zero
+cost (resp. probability).
+*/
+func (a Automaton) ToJSON(id int) JSONAutomaton {
+	var n Network
+	for _, transition := range a.Transitions {
+		n.Weighted = n.Weighted || transition.Cost != 0
+		n.Probabilistic = n.Probabilistic || transition.Probability != 0
+	}
+	return a.toJSON(id, n)
+}
+
+/*
+Build the json representation of an automaton of the network
+n, which gives the names of the labels and tells if the costs
+and probabilities of the transitions must be given
+*/
+func (a Automaton) toJSON(id int, n Network) JSONAutomaton {
+	labelName := n.LabelName
+
+	// Name
+	var jAutomaton JSONAutomaton
+	jAutomaton.Name = a.nameOf(id)
+
+	// States
+	jAutomaton.States = make([]string, a.NumStates)
+	for i := 0; i < a.NumStates; i++ {
+		jAutomaton.States[i] = a.stateNameOf(i)
+	}
+
+	// InputSymbols
+	labels := make([]int, len(a.Labels))
+	copy(labels, a.Labels)
+	sort.Ints(labels)
+	jAutomaton.InputSymbols = make([]string, len(labels))
+	for i, label := range labels {
+		jAutomaton.InputSymbols[i] = labelName(label)
+	}
+
+	// Transitions
+	transitions := make([]Transition, len(a.Transitions))
+	copy(transitions, a.Transitions)
+	sortTransitions(transitions)
+	jAutomaton.Transitions.Content = make([]JSONStateTransitions, 0)
+	for i, transition := range transitions {
+		jTransition := JSONTransition{
+			To:    a.stateNameOf(transition.To),
+			Label: labelName(transition.Label),
+		}
+		if n.Probabilistic && transition.Label != TauLabel {
+			jTransition.Probability = n.probabilityValue(transition.Probability)
+		}
+		if i == 0 || transition.From != transitions[i-1].From {
+			jAutomaton.Transitions.Content = append(jAutomaton.Transitions.Content, JSONStateTransitions{
+				From: a.stateNameOf(transition.From),
+			})
+		}
+		last := &jAutomaton.Transitions.Content[len(jAutomaton.Transitions.Content)-1]
+		last.Transitions = append(last.Transitions, jTransition)
+	}
+
+	// Costs
+	if n.Weighted {
+		jAutomaton.Costs = &JSONCosts{Content: make([]JSONStateCosts, 0)}
+		for i, transition := range transitions {
+			if i == 0 || transition.From != transitions[i-1].From {
+				jAutomaton.Costs.Content = append(jAutomaton.Costs.Content, JSONStateCosts{
+					From: a.stateNameOf(transition.From),
+				})
+			}
+			if i > 0 && transition.From == transitions[i-1].From && transition.Label == transitions[i-1].Label {
+				continue
+			}
+			last := &jAutomaton.Costs.Content[len(jAutomaton.Costs.Content)-1]
+			last.Costs = append(last.Costs, JSONCost{
+				Label: labelName(transition.Label),
+				Cost:  transition.Cost,
+			})
+		}
+	}
+
+	// InitialState
+	jAutomaton.InitialState = a.stateNameOf(0)
+
+	//FinalStates
+	goalStates := make([]int, len(a.GoalStates))
+	copy(goalStates, a.GoalStates)
+	sort.Ints(goalStates)
+	jAutomaton.FinalStates = make([]string, len(goalStates))
+	for i, stateNum := range goalStates {
+		jAutomaton.FinalStates[i] = a.stateNameOf(stateNum)
+	}
+
+	return jAutomaton
+
+}
+
+/*
+Value of a probability in the json representation of the
+network, a fraction for rational probabilities
+*/
+func (n Network) probabilityValue(probability float64) interface{} {
+	if n.ProbabilityDenominator > 0 {
+		return n.probabilityString(probability)
+	}
+	return probability
+}
+
+/*
+Text of a probability, a reduced fraction
+for rational probabilities
+*/
+func (n Network) probabilityString(probability float64) string {
+	if n.ProbabilityDenominator > 0 {
+		numerator := int(math.Round(probability * float64(n.ProbabilityDenominator)))
+		denominator := n.ProbabilityDenominator
+		divisor := gcd(numerator, denominator)
+		numerator, denominator = numerator/divisor, denominator/divisor
+		if denominator == 1 {
+			return strconv.Itoa(numerator)
+		}
+		return fmt.Sprint(numerator, "/", denominator)
+	}
+	return strconv.FormatFloat(probability, 'g', -1, 64)
+}
+
+/*
+Greatest common divisor of two non-negative integers
+*/
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+/*
+Sort transitions by origin state, label and target state
+*/
+func sortTransitions(transitions []Transition) {
+	sort.Slice(transitions, func(i, j int) bool {
+		if transitions[i].From != transitions[j].From {
+			return transitions[i].From < transitions[j].From
+		}
+		if transitions[i].Label != transitions[j].Label {
+			return transitions[i].Label < transitions[j].Label
+		}
+		return transitions[i].To < transitions[j].To
+	})
+}
+
+/*
+SHA-256 of the canonical json representation of the automata
+of a network. The metadata are not taken into account, so two
+identical networks generated from different configurations have
+the same hash.
+*/
+func (n Network) Hash() (string, error) {
+	h := sha256.New()
+	jw := newJSONWriter(h)
+	n.writeJSONAutomata(jw)
+	if err := jw.flush(); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,231 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+/*
+Options of the json output
+*/
+type JSONOptions struct {
+	// add the interaction graph of the network
+	InteractionGraph bool
+}
+
+/*
+Write the json representation of a network. The automata are
+converted and written one at a time, so that the representation
+of the whole network is never held in memory. The output is the
+same as the one of json.Marshal on the result of ToJSON, the
+metadata block being omitted for networks read without one.
+*/
+func (n Network) WriteJSON(w io.Writer, options JSONOptions) error {
+	jw := newJSONWriter(w)
+	jw.raw("{")
+	if !n.NoMetadata {
+		jw.raw(`"metadata":`)
+		jw.value(n.jsonMetadata())
+		jw.raw(",")
+	}
+	jw.raw(`"automata":`)
+	n.writeJSONAutomata(jw)
+	if options.InteractionGraph {
+		jw.raw(`,"interaction_graph":`)
+		jw.value(n.InteractionGraph().ToJSON())
+	}
+	jw.raw("}")
+
+	return jw.flush()
+}
+
+/*
+Write the json array of the automata of a network
+*/
+func (n Network) writeJSONAutomata(jw *jsonWriter) {
+	jw.raw("[")
+	for i, a := range n.Automata {
+		if i > 0 {
+			jw.raw(",")
+		}
+		a.toJSON(i, n).writeJSON(jw)
+	}
+	jw.raw("]")
+}
+
+func (jAutomaton JSONAutomaton) writeJSON(jw *jsonWriter) {
+	jw.raw(`{"name":`)
+	jw.str(jAutomaton.Name)
+	jw.raw(`,"states":`)
+	jw.strs(jAutomaton.States)
+	jw.raw(`,"input_symbols":`)
+	jw.strs(jAutomaton.InputSymbols)
+	jw.raw(`,"transitions":`)
+	jAutomaton.Transitions.writeJSON(jw)
+	if jAutomaton.Costs != nil {
+		jw.raw(`,"costs":`)
+		jAutomaton.Costs.writeJSON(jw)
+	}
+	jw.raw(`,"initial_state":`)
+	jw.str(jAutomaton.InitialState)
+	jw.raw(`,"final_states":`)
+	jw.strs(jAutomaton.FinalStates)
+	jw.raw("}")
+}
+
+func (jsonTrans JSONTransitions) writeJSON(jw *jsonWriter) {
+	jw.raw("{")
+	for i, stateTransitions := range jsonTrans.Content {
+		if i > 0 {
+			jw.raw(",")
+		}
+		jw.str(stateTransitions.From)
+		jw.raw(":{")
+		// consecutive transitions with the same label are
+		// written as an array of targets, or as an object
+		// giving the probability of each target
+		transitions := stateTransitions.Transitions
+		for j := 0; j < len(transitions); {
+			if j > 0 {
+				jw.raw(",")
+			}
+			jw.str(transitions[j].Label)
+			jw.raw(":")
+			k := j + 1
+			for k < len(transitions) && transitions[k].Label == transitions[j].Label {
+				k++
+			}
+			if transitions[j].Probability != nil {
+				jw.raw("{")
+				for l := j; l < k; l++ {
+					if l > j {
+						jw.raw(",")
+					}
+					jw.str(transitions[l].To)
+					jw.raw(":")
+					jw.value(transitions[l].Probability)
+				}
+				jw.raw("}")
+			} else if k == j+1 {
+				jw.str(transitions[j].To)
+			} else {
+				jw.raw("[")
+				for l := j; l < k; l++ {
+					if l > j {
+						jw.raw(",")
+					}
+					jw.str(transitions[l].To)
+				}
+				jw.raw("]")
+			}
+			j = k
+		}
+		jw.raw("}")
+	}
+	jw.raw("}")
+}
+
+func (jsonCosts JSONCosts) writeJSON(jw *jsonWriter) {
+	jw.raw("{")
+	for i, stateCosts := range jsonCosts.Content {
+		if i > 0 {
+			jw.raw(",")
+		}
+		jw.str(stateCosts.From)
+		jw.raw(":{")
+		for j, cost := range stateCosts.Costs {
+			if j > 0 {
+				jw.raw(",")
+			}
+			jw.str(cost.Label)
+			jw.raw(":")
+			jw.value(cost.Cost)
+		}
+		jw.raw("}")
+	}
+	jw.raw("}")
+}
+
+/*
+Buffered writer of json values, the first error
+encountered stops the writing and is kept
+*/
+type jsonWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func newJSONWriter(w io.Writer) *jsonWriter {
+	return &jsonWriter{w: bufio.NewWriter(w)}
+}
+
+func (jw *jsonWriter) raw(s string) {
+	if jw.err == nil {
+		_, jw.err = jw.w.WriteString(s)
+	}
+}
+
+/*
+Write a string with json escaping
+*/
+func (jw *jsonWriter) str(s string) {
+	jw.value(s)
+}
+
+/*
+Write an array of strings
+*/
+func (jw *jsonWriter) strs(ss []string) {
+	if ss == nil {
+		jw.raw("null")
+		return
+	}
+	jw.raw("[")
+	for i, s := range ss {
+		if i > 0 {
+			jw.raw(",")
+		}
+		jw.str(s)
+	}
+	jw.raw("]")
+}
+
+/*
+Write any value, using encoding/json
+*/
+func (jw *jsonWriter) value(v interface{}) {
+	if jw.err != nil {
+		return
+	}
+	var data []byte
+	data, jw.err = json.Marshal(v)
+	if jw.err == nil {
+		_, jw.err = jw.w.Write(data)
+	}
+}
+
+func (jw *jsonWriter) flush() error {
+	if jw.err != nil {
+		return jw.err
+	}
+	return jw.w.Flush()
+}
@@ -0,0 +1,495 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"fmt"
+	"log"
+)
+
+// distributions of the number of automata per shared label
+const (
+	ArityUniform   = "uniform"
+	ArityGeometric = "geometric"
+)
+
+/*
+Minimum number of private labels of an automaton, at most
+MinNumLabelsPerAutomaton - 1 so that an automaton with
+MinNumLabelsPerAutomaton labels has a public one. It does not
+depend on the number of labels drawn for the automaton, which can
+get more labels afterwards, so that it can also be checked on
+generated networks.
+*/
+func (config Configuration) minNumPrivateLabels() int {
+	if config.MinNumPrivateLabelsPerAutomaton > config.MinNumLabelsPerAutomaton-1 {
+		return config.MinNumLabelsPerAutomaton - 1
+	}
+	return config.MinNumPrivateLabelsPerAutomaton
+}
+
+/*
+Draw the number of labels of an automaton and
+how many of these labels are private.
+*/
+func (gen *Generator) drawNumLabels() (numLabels, numPrivateLabels int) {
+	r := gen.rand
+	config := gen.config
+
+	numLabels = r.Intn(config.MaxNumLabelsPerAutomaton-config.MinNumLabelsPerAutomaton+1) + config.MinNumLabelsPerAutomaton
+	maxPrivateLabels := config.MaxNumPrivateLabelsPerAutomaton
+	if maxPrivateLabels > numLabels-1 {
+		maxPrivateLabels = numLabels - 1
+	}
+	minPrivateLabels := config.minNumPrivateLabels()
+	if minPrivateLabels > maxPrivateLabels {
+		minPrivateLabels = maxPrivateLabels
+	}
+	numPrivateLabels = r.Intn(maxPrivateLabels-minPrivateLabels+1) + minPrivateLabels
+	return numLabels, numPrivateLabels
+}
+
+/*
+Check if the number of automata sharing each label is controlled
+*/
+func (gen *Generator) arityBounded() bool {
+	return gen.config.MinNumAutomataPerSharedLabel > 0 || gen.config.MaxNumAutomataPerSharedLabel > 0
+}
+
+/*
+Draw the number of automata which should share a public label
+*/
+func (gen *Generator) drawArity() int {
+	r := gen.rand
+	config := gen.config
+
+	minArity := config.MinNumAutomataPerSharedLabel
+	if minArity < 2 {
+		minArity = 2
+	}
+	maxArity := config.MaxNumAutomataPerSharedLabel
+	if maxArity == 0 || maxArity > config.NumAutomata {
+		maxArity = config.NumAutomata
+	}
+	if maxArity < minArity {
+		return maxArity
+	}
+
+	switch config.SharedLabelArityDistribution {
+	case ArityGeometric:
+		// each additional automaton with probability 1/2
+		arity := minArity
+		for arity < maxArity && r.Intn(2) == 0 {
+			arity++
+		}
+		return arity
+	default:
+		return r.Intn(maxArity-minArity+1) + minArity
+	}
+}
+
+/*
+State of the random allocation of labels: the automata are
+considered one after the other, each one sharing some of the
+public labels of the previous ones. When the number of automata
+per shared label is controlled, arity gives the number of automata
+which should share each public label and count the number of
+automata which already have it.
+*/
+type randomLabels struct {
+	lastLabel int
+	allLabels []int
+	arity     map[int]int
+	count     map[int]int
+}
+
+/*
+Make a label public
+*/
+func (gen *Generator) publish(rl *randomLabels, label int) {
+	rl.allLabels = append(rl.allLabels, label)
+	if gen.arityBounded() {
+		if rl.arity == nil {
+			rl.arity = make(map[int]int)
+			rl.count = make(map[int]int)
+		}
+		rl.arity[label] = gen.drawArity()
+		rl.count[label] = 1
+	}
+}
+
+/*
+Build the set of labels of the next automaton
+*/
+func (gen *Generator) nextRandomLabels(rl *randomLabels) []int {
+	r := gen.rand
+
+	// determine numbers of labels and private labels
+	numLabels, numPrivateLabels := gen.drawNumLabels()
+	// build a set of labels
+	labels := make([]int, numLabels)
+	if rl.lastLabel == 0 {
+		for i := 0; i < numLabels; i++ {
+			labels[i] = i
+			if i < numLabels-numPrivateLabels {
+				gen.publish(rl, i)
+			}
+		}
+		rl.lastLabel += numLabels
+	} else {
+		numSharedLabelsFromPrevious := r.Intn(numLabels-numPrivateLabels) + 1
+		if rl.arity != nil {
+			// the labels shared by too few automata take all the
+			// public labels they can, so that few of them remain
+			// to be shared when all the automata are built
+			numSharedLabelsFromPrevious = numLabels - numPrivateLabels
+		}
+		if numSharedLabelsFromPrevious > len(rl.allLabels) {
+			numSharedLabelsFromPrevious = len(rl.allLabels)
+		}
+		if numSharedLabelsFromPrevious > 0 {
+			r.Shuffle(len(rl.allLabels), func(i, j int) {
+				rl.allLabels[i], rl.allLabels[j] = rl.allLabels[j], rl.allLabels[i]
+			})
+			for i := 0; i < numSharedLabelsFromPrevious; i++ {
+				labels[i] = rl.allLabels[i]
+			}
+			if rl.arity != nil {
+				// labels shared by enough automata are not public anymore
+				open := rl.allLabels[:0]
+				for i, label := range rl.allLabels {
+					if i < numSharedLabelsFromPrevious {
+						rl.count[label]++
+					}
+					if rl.count[label] < rl.arity[label] {
+						open = append(open, label)
+					}
+				}
+				rl.allLabels = open
+			}
+		}
+		for i := numSharedLabelsFromPrevious; i < numLabels; i++ {
+			rl.lastLabel++
+			labels[i] = rl.lastLabel
+			if i < numLabels-numPrivateLabels {
+				gen.publish(rl, rl.lastLabel)
+			}
+		}
+	}
+	return labels
+}
+
+/*
+Build the sets of labels of all the automata from the edges
+of an interaction graph: each edge gets its own shared label,
+then more labels are shared along the edges while both of their
+ends need more public labels, then the automata which still need
+public labels share more labels with the neighbours having room
+for them, and finally private labels are added, up to the drawn
+number of labels of each automaton. An automaton with more neighbours than public labels
+gets one label per neighbour anyway. When exact is true, labels
+are only shared by automata which are all linked by edges, so
+that the interaction graph is exactly the given one.
+*/
+func (gen *Generator) labelsFromEdges(numAutomata int, edges [][2]int, exact bool) ([][]int, error) {
+	r := gen.rand
+
+	numLabels := make([]int, numAutomata)
+	numPublicLabels := make([]int, numAutomata)
+	numPrivateLabels := make([]int, numAutomata)
+	neighbours := make([][]int, numAutomata)
+	for _, edge := range edges {
+		neighbours[edge[0]] = append(neighbours[edge[0]], edge[1])
+		neighbours[edge[1]] = append(neighbours[edge[1]], edge[0])
+	}
+	for i := 0; i < numAutomata; i++ {
+		var numPrivate int
+		numLabels[i], numPrivate = gen.drawNumLabels()
+		numPublicLabels[i] = numLabels[i] - numPrivate
+		numPrivateLabels[i] = numPrivate
+		if len(neighbours[i]) == 0 {
+			// an isolated automaton cannot share labels
+			numPublicLabels[i] = 0
+			numPrivateLabels[i] = numLabels[i]
+		}
+	}
+
+	labels := make([][]int, numAutomata)
+	lastLabel := -1
+
+	// one label per edge
+	order := make([]int, len(edges))
+	for i := range order {
+		order[i] = i
+	}
+	r.Shuffle(len(order), func(i, j int) {
+		order[i], order[j] = order[j], order[i]
+	})
+	for _, e := range order {
+		lastLabel++
+		labels[edges[e][0]] = append(labels[edges[e][0]], lastLabel)
+		labels[edges[e][1]] = append(labels[edges[e][1]], lastLabel)
+	}
+
+	// more shared labels where needed
+	for added := true; added; {
+		added = false
+		for _, e := range order {
+			first, second := edges[e][0], edges[e][1]
+			if len(labels[first]) < numPublicLabels[first] && len(labels[second]) < numPublicLabels[second] {
+				lastLabel++
+				labels[first] = append(labels[first], lastLabel)
+				labels[second] = append(labels[second], lastLabel)
+				added = true
+			}
+		}
+	}
+
+	// the automata which still need public labels share more labels
+	// with the neighbours which have room for them
+	maxLabels := gen.config.MaxNumLabelsPerAutomaton
+	for i := 0; i < numAutomata; i++ {
+		for len(labels[i]) < numPublicLabels[i] {
+			withRoom := make([]int, 0)
+			for _, j := range neighbours[i] {
+				if len(labels[j])+numPrivateLabels[j] < maxLabels {
+					withRoom = append(withRoom, j)
+				}
+			}
+			if len(withRoom) == 0 {
+				break
+			}
+			j := withRoom[r.Intn(len(withRoom))]
+			lastLabel++
+			labels[i] = append(labels[i], lastLabel)
+			labels[j] = append(labels[j], lastLabel)
+		}
+	}
+
+	// labels shared by more than two automata
+	if gen.arityBounded() {
+		var adjacent map[[2]int]bool
+		if exact {
+			adjacent = make(map[[2]int]bool)
+			for _, edge := range edges {
+				adjacent[[2]int{edge[0], edge[1]}] = true
+				adjacent[[2]int{edge[1], edge[0]}] = true
+			}
+		}
+		if err := gen.extendSharedLabels(labels, lastLabel, numPrivateLabels, adjacent); err != nil {
+			return nil, err
+		}
+	}
+
+	// private labels, more of them for the automata which
+	// could not get all their public labels
+	for i := 0; i < numAutomata; i++ {
+		if missing := numLabels[i] - len(labels[i]) - numPrivateLabels[i]; missing > 0 {
+			numPrivateLabels[i] += missing
+		}
+		for j := 0; j < numPrivateLabels[i]; j++ {
+			lastLabel++
+			labels[i] = append(labels[i], lastLabel)
+		}
+	}
+
+	return labels, nil
+}
+
+/*
+Give more automata to the shared labels of an allocation where
+each shared label has exactly two automata. The automata are
+added one at a time, among the neighbours of the ones which
+already have the label, up to the drawn number of automata
+for the label when possible. Automata which can get the label
+without exceeding MaxNumLabelsPerAutomaton, with the private
+labels they will get, are preferred. When adjacent is not nil,
+the automata of a label must all be adjacent, an error being
+returned if some label cannot get MinNumAutomataPerSharedLabel
+automata this way.
+*/
+func (gen *Generator) extendSharedLabels(labels [][]int, lastLabel int, numPrivateLabels []int, adjacent map[[2]int]bool) error {
+	r := gen.rand
+	maxLabels := gen.config.MaxNumLabelsPerAutomaton
+	numBeyond := 0
+
+	// automata of each label and neighbours of each automaton
+	holders := make([][]int, lastLabel+1)
+	neighbours := make([][]int, len(labels))
+	for i, automatonLabels := range labels {
+		for _, label := range automatonLabels {
+			holders[label] = append(holders[label], i)
+		}
+	}
+	for _, automata := range holders {
+		neighbours[automata[0]] = append(neighbours[automata[0]], automata[1])
+		neighbours[automata[1]] = append(neighbours[automata[1]], automata[0])
+	}
+
+	for label, automata := range holders {
+		arity := gen.drawArity()
+		has := make(map[int]bool)
+		for _, i := range automata {
+			has[i] = true
+		}
+		for len(automata) < arity {
+			candidates := make([]int, 0)
+			withRoom := make([]int, 0)
+			for _, i := range automata {
+				for _, j := range neighbours[i] {
+					if !has[j] && (adjacent == nil || adjacentToAll(adjacent, j, automata)) {
+						candidates = append(candidates, j)
+						if len(labels[j])+numPrivateLabels[j] < maxLabels {
+							withRoom = append(withRoom, j)
+						}
+					}
+				}
+			}
+			if len(candidates) == 0 {
+				if adjacent != nil && len(automata) < gen.config.MinNumAutomataPerSharedLabel {
+					return ConfigurationError{Violations: []string{fmt.Sprint(
+						"MinNumAutomataPerSharedLabel (", gen.config.MinNumAutomataPerSharedLabel,
+						") cannot be respected without adding edges to the interaction graph, label ",
+						actionName, label, " can only be shared by ", len(automata), " automata",
+					)}}
+				}
+				log.Print("Label ", actionName, label, " cannot be shared by more than ", len(automata), " automata")
+				break
+			}
+			if len(withRoom) > 0 {
+				candidates = withRoom
+			} else {
+				numBeyond++
+			}
+			j := candidates[r.Intn(len(candidates))]
+			has[j] = true
+			automata = append(automata, j)
+			labels[j] = append(labels[j], label)
+		}
+	}
+
+	if numBeyond > 0 {
+		log.Print(
+			"Warning: ", numBeyond, " labels given to automata beyond MaxNumLabelsPerAutomaton (",
+			maxLabels, ") for the shared labels to be shared by enough automata",
+		)
+	}
+	return nil
+}
+
+/*
+Check if an automaton is adjacent to all the automata of a list
+*/
+func adjacentToAll(adjacent map[[2]int]bool, automaton int, automata []int) bool {
+	for _, i := range automata {
+		if !adjacent[[2]int{automaton, i}] {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+Give more automata to the public labels of a random allocation
+which are shared by less automata than drawn for them, the new
+automata being chosen at random among the ones with less than
+MaxNumLabelsPerAutomaton labels. A label stays shared by less
+automata than drawn when no automaton has room for it, which is
+an error in strict mode if MinNumAutomataPerSharedLabel is not
+respected.
+*/
+func (gen *Generator) padSharedLabels(labels [][]int, rl *randomLabels) error {
+	holders := make(map[int]map[int]bool)
+	for i, automatonLabels := range labels {
+		for _, label := range automatonLabels {
+			if _, public := rl.arity[label]; public {
+				if holders[label] == nil {
+					holders[label] = make(map[int]bool)
+				}
+				holders[label][i] = true
+			}
+		}
+	}
+
+	numShort := 0
+	for label := 0; label <= rl.lastLabel; label++ {
+		arity, public := rl.arity[label]
+		if !public {
+			continue
+		}
+		has := holders[label]
+		for len(has) < arity && len(has) < len(labels) {
+			i, found := gen.chooseNewHolder(labels, has)
+			if !found {
+				break
+			}
+			has[i] = true
+			labels[i] = append(labels[i], label)
+		}
+		if len(has) < gen.config.MinNumAutomataPerSharedLabel && len(has) < len(labels) {
+			if gen.strict {
+				return ConfigurationError{Violations: []string{fmt.Sprint(
+					"MinNumAutomataPerSharedLabel (", gen.config.MinNumAutomataPerSharedLabel,
+					") cannot be respected without exceeding MaxNumLabelsPerAutomaton (",
+					gen.config.MaxNumLabelsPerAutomaton, "), label ", actionName, label,
+					" can only be shared by ", len(has), " automata",
+				)}}
+			}
+			numShort++
+		}
+	}
+
+	if numShort > 0 {
+		log.Print(
+			"Warning: ", numShort, " public labels shared by less than MinNumAutomataPerSharedLabel (",
+			gen.config.MinNumAutomataPerSharedLabel, ") automata for MaxNumLabelsPerAutomaton (",
+			gen.config.MaxNumLabelsPerAutomaton, ") to be respected",
+		)
+	}
+	return nil
+}
+
+/*
+Choose at random an automaton which does not have a label,
+among the ones with less than MaxNumLabelsPerAutomaton labels
+(found is false if there is none). Random automata are tried
+first, all the automata being considered only when these tries fail.
+*/
+func (gen *Generator) chooseNewHolder(labels [][]int, has map[int]bool) (i int, found bool) {
+	r := gen.rand
+	maxLabels := gen.config.MaxNumLabelsPerAutomaton
+
+	for try := 0; try < len(labels); try++ {
+		i := r.Intn(len(labels))
+		if !has[i] && len(labels[i]) < maxLabels {
+			return i, true
+		}
+	}
+
+	candidates := make([]int, 0)
+	for i := range labels {
+		if !has[i] && len(labels[i]) < maxLabels {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return 0, false
+	}
+	return candidates[r.Intn(len(candidates))], true
+}
@@ -0,0 +1,71 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"testing"
+)
+
+/*
+Every automaton gets at least MinNumLabelsPerAutomaton labels,
+whatever the topology, even the ones with few neighbours
+*/
+func TestMinNumLabelsPerAutomaton(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	for _, topology := range topologies {
+		if topology == TopologyFromFile {
+			continue
+		}
+		for _, numAutomata := range []int{4, 9} {
+			for seed := int64(1); seed <= 20; seed++ {
+				config := Configuration{
+					MinNumStatesPerAutomaton:        2,
+					MaxNumStatesPerAutomaton:        4,
+					MinNumGoalStatesPerAutomaton:    1,
+					MaxNumGoalStatesPerAutomaton:    1,
+					MinNumLabelsPerAutomaton:        4,
+					MaxNumLabelsPerAutomaton:        5,
+					MinNumPrivateLabelsPerAutomaton: 1,
+					MaxNumPrivateLabelsPerAutomaton: 2,
+					MinNumTransitionsPerAutomaton:   1,
+					NumAutomata:                     numAutomata,
+					Topology:                        topology,
+					Seed:                            seed,
+				}
+				network, err := New(config, nil).Generate()
+				if err != nil {
+					t.Fatalf("topology %s, %d automata, seed %d: %v", topology, numAutomata, seed, err)
+				}
+				for i, a := range network.Automata {
+					if len(a.Labels) < config.MinNumLabelsPerAutomaton {
+						t.Errorf(
+							"topology %s, %d automata, seed %d: automaton %d has %d labels, less than MinNumLabelsPerAutomaton (%d)",
+							topology, numAutomata, seed, i, len(a.Labels), config.MinNumLabelsPerAutomaton,
+						)
+					}
+				}
+			}
+		}
+	}
+}
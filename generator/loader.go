@@ -0,0 +1,716 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+Read a network from a json file in the output format of noag,
+see ReadJSON
+*/
+func ReadNetworkFile(file string) (Network, error) {
+	jNetwork, err := ReadJSONFile(file)
+	if err != nil {
+		return Network{}, err
+	}
+	n, err := jNetwork.Network()
+	if err != nil {
+		return Network{}, fmt.Errorf("invalid network file %s: %w", file, err)
+	}
+	return n, nil
+}
+
+/*
+Read the json representation of a network from a file, without
+checking its content, see ReadJSON
+*/
+func ReadJSONFile(file string) (JSONNetwork, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return JSONNetwork{}, fmt.Errorf("cannot open network file %s: %w", file, err)
+	}
+	defer f.Close()
+
+	jNetwork, err := ReadJSON(f)
+	if err != nil {
+		return JSONNetwork{}, fmt.Errorf("cannot parse network file %s: %w", file, err)
+	}
+	return jNetwork, nil
+}
+
+/*
+Read the json representation of a network, either an object
+with a metadata block and an array of automata (the output
+format of noag) or just an array of automata. Errors give the
+line where they occur. Transitions are kept in the order of
+the input, including duplicated ones.
+*/
+func ReadJSON(r io.Reader) (JSONNetwork, error) {
+	lc := &lineCounter{r: r}
+	jr := jsonReader{dec: json.NewDecoder(lc), lines: lc}
+
+	var jNetwork JSONNetwork
+	tok, err := jr.token()
+	if err != nil {
+		return jNetwork, err
+	}
+	switch tok {
+	case json.Delim('['):
+		jNetwork.Automata, err = jr.automataItems()
+	case json.Delim('{'):
+		err = jr.object(func(key string) error {
+			switch key {
+			case "metadata":
+				jNetwork.hasMetadata = true
+				return jr.decode(&jNetwork.Metadata)
+			case "automata":
+				if err := jr.expectDelim('['); err != nil {
+					return err
+				}
+				var err error
+				jNetwork.Automata, err = jr.automataItems()
+				return err
+			case "interaction_graph":
+				return jr.decode(&jNetwork.InteractionGraph)
+			default:
+				return jr.errorf("unknown field %q", key)
+			}
+		})
+	default:
+		return jNetwork, jr.errorf("expecting an object or an array")
+	}
+	if err != nil {
+		return jNetwork, err
+	}
+
+	if _, err := jr.dec.Token(); err != io.EOF {
+		return jNetwork, jr.errorf("unexpected data after the network")
+	}
+	return jNetwork, nil
+}
+
+/*
+Reader of json data keeping track of the positions
+of new lines, for error messages
+*/
+type jsonReader struct {
+	dec   *json.Decoder
+	lines *lineCounter
+}
+
+/*
+Line of the last token read
+*/
+func (jr jsonReader) line() int {
+	return jr.lines.lineAt(jr.dec.InputOffset())
+}
+
+func (jr jsonReader) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", jr.line(), fmt.Sprintf(format, args...))
+}
+
+/*
+Give the line of json syntax errors
+*/
+func (jr jsonReader) wrap(err error) error {
+	var syntaxError *json.SyntaxError
+	switch {
+	case err == io.EOF:
+		return jr.errorf("unexpected end of input")
+	case errors.As(err, &syntaxError):
+		return fmt.Errorf("line %d: %s", jr.lines.lineAt(syntaxError.Offset), syntaxError)
+	case err != nil:
+		return jr.errorf("%s", err)
+	}
+	return nil
+}
+
+func (jr jsonReader) token() (json.Token, error) {
+	tok, err := jr.dec.Token()
+	if err != nil {
+		return nil, jr.wrap(err)
+	}
+	return tok, nil
+}
+
+/*
+Decode the next value, the offsets of type errors
+being relative to the start of this value
+*/
+func (jr jsonReader) decode(v interface{}) error {
+	start := jr.dec.InputOffset()
+	err := jr.dec.Decode(v)
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) {
+		return fmt.Errorf("line %d: cannot read %s as %s", jr.lines.lineAt(start+typeError.Offset), typeError.Value, typeError.Type)
+	}
+	return jr.wrap(err)
+}
+
+func (jr jsonReader) expectDelim(delim json.Delim) error {
+	tok, err := jr.token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return jr.errorf("expecting %q", delim)
+	}
+	return nil
+}
+
+/*
+Read the fields of an object whose opening brace was
+already read, calling field for the value of each key
+*/
+func (jr jsonReader) object(field func(key string) error) error {
+	for jr.dec.More() {
+		tok, err := jr.token()
+		if err != nil {
+			return err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return jr.errorf("expecting a field name")
+		}
+		if err := field(key); err != nil {
+			return err
+		}
+	}
+	_, err := jr.token()
+	return err
+}
+
+/*
+Read automata up to the end of an array
+whose opening bracket was already read
+*/
+func (jr jsonReader) automataItems() ([]JSONAutomaton, error) {
+	automata := make([]JSONAutomaton, 0)
+	for jr.dec.More() {
+		if err := jr.expectDelim('{'); err != nil {
+			return nil, err
+		}
+		jAutomaton := JSONAutomaton{line: jr.line()}
+		err := jr.object(func(key string) error {
+			switch key {
+			case "name":
+				return jr.decode(&jAutomaton.Name)
+			case "states":
+				return jr.decode(&jAutomaton.States)
+			case "input_symbols":
+				return jr.decode(&jAutomaton.InputSymbols)
+			case "transitions":
+				return jr.transitions(&jAutomaton.Transitions)
+			case "costs":
+				jAutomaton.Costs = &JSONCosts{}
+				return jr.costs(jAutomaton.Costs)
+			case "initial_state":
+				return jr.decode(&jAutomaton.InitialState)
+			case "final_states":
+				return jr.decode(&jAutomaton.FinalStates)
+			default:
+				return jr.errorf("unknown field %q in automaton", key)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		automata = append(automata, jAutomaton)
+	}
+	_, err := jr.token()
+	return automata, err
+}
+
+/*
+Read the transitions of an automaton: an object giving for
+each origin state an object giving for each label the target,
+an array of targets for nondeterministic automata or an object
+giving the probability of each target for probabilistic automata
+*/
+func (jr jsonReader) transitions(jsonTrans *JSONTransitions) error {
+	if err := jr.expectDelim('{'); err != nil {
+		return err
+	}
+	jsonTrans.Content = make([]JSONStateTransitions, 0)
+	return jr.object(func(from string) error {
+		stateTransitions := JSONStateTransitions{From: from, line: jr.line()}
+		if err := jr.expectDelim('{'); err != nil {
+			return err
+		}
+		err := jr.object(func(label string) error {
+			var targets jsonTargets
+			if err := jr.decode(&targets); err != nil {
+				return err
+			}
+			for _, target := range targets {
+				stateTransitions.Transitions = append(stateTransitions.Transitions, JSONTransition{
+					To:          target.to,
+					Label:       label,
+					Probability: target.probability,
+					line:        jr.line(),
+				})
+			}
+			return nil
+		})
+		jsonTrans.Content = append(jsonTrans.Content, stateTransitions)
+		return err
+	})
+}
+
+/*
+Read the costs of the transitions of an automaton: an object
+giving for each origin state an object giving for each label
+the cost of the transitions with this label from this state
+*/
+func (jr jsonReader) costs(jsonCosts *JSONCosts) error {
+	if err := jr.expectDelim('{'); err != nil {
+		return err
+	}
+	jsonCosts.Content = make([]JSONStateCosts, 0)
+	return jr.object(func(from string) error {
+		stateCosts := JSONStateCosts{From: from, line: jr.line()}
+		if err := jr.expectDelim('{'); err != nil {
+			return err
+		}
+		err := jr.object(func(label string) error {
+			var cost float64
+			if err := jr.decode(&cost); err != nil {
+				return err
+			}
+			stateCosts.Costs = append(stateCosts.Costs, JSONCost{
+				Label: label,
+				Cost:  cost,
+				line:  jr.line(),
+			})
+			return nil
+		})
+		jsonCosts.Content = append(jsonCosts.Content, stateCosts)
+		return err
+	})
+}
+
+/*
+Targets of the transitions from a state with a label, either
+a state, an array of states or an object giving the probability
+(a fraction in a string or a number) of each state
+*/
+type jsonTargets []jsonTarget
+
+type jsonTarget struct {
+	to          string
+	probability interface{}
+}
+
+func (targets *jsonTargets) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '[' {
+		var states []string
+		if err := json.Unmarshal(data, &states); err != nil {
+			return err
+		}
+		for _, to := range states {
+			*targets = append(*targets, jsonTarget{to: to})
+		}
+		return nil
+	}
+	if len(data) > 0 && data[0] == '{' {
+		// the order of the targets is kept
+		dec := json.NewDecoder(bytes.NewReader(data))
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			to, _ := tok.(string)
+			tok, err = dec.Token()
+			if err != nil {
+				return err
+			}
+			switch tok.(type) {
+			case string, float64:
+			default:
+				return fmt.Errorf("the probability of state %q should be a string or a number", to)
+			}
+			*targets = append(*targets, jsonTarget{to: to, probability: tok})
+		}
+		return nil
+	}
+	var to string
+	if err := json.Unmarshal(data, &to); err != nil {
+		return err
+	}
+	*targets = jsonTargets{{to: to}}
+	return nil
+}
+
+/*
+Reader recording the offsets of new lines
+*/
+type lineCounter struct {
+	r        io.Reader
+	offset   int64
+	newLines []int64
+}
+
+func (lc *lineCounter) Read(p []byte) (int, error) {
+	n, err := lc.r.Read(p)
+	for i := 0; i < n; i++ {
+		if p[i] == '\n' {
+			lc.newLines = append(lc.newLines, lc.offset+int64(i))
+		}
+	}
+	lc.offset += int64(n)
+	return n, err
+}
+
+/*
+Line (starting at 1) of the byte at some offset
+*/
+func (lc *lineCounter) lineAt(offset int64) int {
+	return sort.Search(len(lc.newLines), func(i int) bool {
+		return lc.newLines[i] >= offset
+	}) + 1
+}
+
+/*
+Build a network from its json representation. States and labels
+named as in the output of noag keep their numbers, otherwise they
+are numbered in order of appearance and their names are kept.
+The initial state of each automaton gets number 0.
+*/
+func (jNetwork JSONNetwork) Network() (Network, error) {
+	n := Network{
+		Configuration:  jNetwork.Metadata.Configuration,
+		Attempts:       jNetwork.Metadata.Attempts,
+		ExternalSource: jNetwork.Metadata.ExternalSource,
+		Automata:       make([]Automaton, len(jNetwork.Automata)),
+		NoMetadata:     !jNetwork.hasMetadata,
+	}
+
+	// labels
+	labelIDs := make(map[string]int)
+	var allLabels []string
+	for _, jAutomaton := range jNetwork.Automata {
+		for _, name := range jAutomaton.InputSymbols {
+			if _, found := labelIDs[name]; !found {
+				labelIDs[name] = len(allLabels)
+				allLabels = append(allLabels, name)
+			}
+		}
+	}
+	if numbers, ok := defaultNumbers(allLabels, actionName); ok {
+		for i, name := range allLabels {
+			labelIDs[name] = numbers[i]
+		}
+	} else {
+		n.LabelNames = make(map[int]string)
+		for i, name := range allLabels {
+			n.LabelNames[i] = name
+		}
+	}
+
+	for i, jAutomaton := range jNetwork.Automata {
+		a, err := jAutomaton.automaton(labelIDs)
+		if err != nil {
+			return n, err
+		}
+		n.Automata[i] = a
+		n.Weighted = n.Weighted || jAutomaton.Costs != nil
+	}
+	if err := jNetwork.assignProbabilities(&n); err != nil {
+		return n, err
+	}
+	if n.Weighted {
+		for i, jAutomaton := range jNetwork.Automata {
+			if jAutomaton.Costs == nil {
+				return n, fmt.Errorf("line %d: automaton %s: no costs in a weighted network", jAutomaton.line, jAutomaton.Name)
+			}
+			if err := jAutomaton.assignCosts(n.Automata[i], labelIDs, n.LabelName); err != nil {
+				return n, err
+			}
+		}
+	}
+
+	// planted plan
+	if jNetwork.Metadata.WitnessPlan != nil {
+		n.Plan = make([]int, len(jNetwork.Metadata.WitnessPlan))
+		for i, name := range jNetwork.Metadata.WitnessPlan {
+			label, found := labelIDs[name]
+			if !found {
+				return n, fmt.Errorf("unknown label %q in the witness plan", name)
+			}
+			n.Plan[i] = label
+		}
+	}
+
+	return n, nil
+}
+
+func (jAutomaton JSONAutomaton) automaton(labelIDs map[string]int) (Automaton, error) {
+	a := Automaton{
+		Name:      jAutomaton.Name,
+		NumStates: len(jAutomaton.States),
+	}
+	errorf := func(line int, format string, args ...interface{}) error {
+		return fmt.Errorf("line %d: automaton %s: %s", line, jAutomaton.Name, fmt.Sprintf(format, args...))
+	}
+
+	// states, the initial one first
+	if len(jAutomaton.States) == 0 {
+		return a, errorf(jAutomaton.line, "no states")
+	}
+	stateIDs := map[string]int{jAutomaton.InitialState: 0}
+	names := []string{jAutomaton.InitialState}
+	declared := make(map[string]bool)
+	for _, name := range jAutomaton.States {
+		if declared[name] {
+			return a, errorf(jAutomaton.line, "state %q declared twice", name)
+		}
+		declared[name] = true
+		if name != jAutomaton.InitialState {
+			stateIDs[name] = len(names)
+			names = append(names, name)
+		}
+	}
+	if !declared[jAutomaton.InitialState] {
+		return a, errorf(jAutomaton.line, "initial state %q is not declared", jAutomaton.InitialState)
+	}
+	if numbers, ok := defaultNumbers(names, stateName); ok && numbers[0] == 0 && isPermutation(numbers) {
+		for i, name := range names {
+			stateIDs[name] = numbers[i]
+		}
+	} else {
+		a.StateNames = names
+	}
+
+	// labels
+	hasLabel := make(map[string]bool)
+	for _, name := range jAutomaton.InputSymbols {
+		if name == tauName {
+			return a, errorf(jAutomaton.line, "label %q is reserved for silent transitions", name)
+		}
+		if hasLabel[name] {
+			return a, errorf(jAutomaton.line, "label %q declared twice", name)
+		}
+		hasLabel[name] = true
+		a.Labels = append(a.Labels, labelIDs[name])
+	}
+
+	// goal states
+	for _, name := range jAutomaton.FinalStates {
+		state, found := stateIDs[name]
+		if !found {
+			return a, errorf(jAutomaton.line, "final state %q is not declared", name)
+		}
+		a.GoalStates = append(a.GoalStates, state)
+	}
+
+	// transitions
+	for _, stateTransitions := range jAutomaton.Transitions.Content {
+		from, found := stateIDs[stateTransitions.From]
+		if !found {
+			return a, errorf(stateTransitions.line, "state %q is not declared", stateTransitions.From)
+		}
+		for _, transition := range stateTransitions.Transitions {
+			to, found := stateIDs[transition.To]
+			if !found {
+				return a, errorf(transition.line, "state %q is not declared", transition.To)
+			}
+			label := TauLabel
+			if transition.Label != tauName {
+				if !hasLabel[transition.Label] {
+					return a, errorf(transition.line, "label %q is not an input symbol", transition.Label)
+				}
+				label = labelIDs[transition.Label]
+			}
+			a.Transitions = append(a.Transitions, Transition{
+				From:  from,
+				To:    to,
+				Label: label,
+			})
+		}
+	}
+
+	return a, nil
+}
+
+/*
+Set the probabilities of the transitions of a network built from
+its json representation. The network is probabilistic if some
+transition has a probability, all its transitions but the silent
+ones must then have one. Probabilities are rational when they are
+all given as strings, their common denominator being kept.
+*/
+func (jNetwork JSONNetwork) assignProbabilities(n *Network) error {
+	for _, jAutomaton := range jNetwork.Automata {
+		for _, stateTransitions := range jAutomaton.Transitions.Content {
+			for _, transition := range stateTransitions.Transitions {
+				n.Probabilistic = n.Probabilistic || transition.Probability != nil
+			}
+		}
+	}
+	if !n.Probabilistic {
+		return nil
+	}
+
+	denominator := big.NewInt(1)
+	rational := true
+	for i, jAutomaton := range jNetwork.Automata {
+		// the transitions of the automaton are in the order of the file
+		pos := 0
+		for _, stateTransitions := range jAutomaton.Transitions.Content {
+			for _, transition := range stateTransitions.Transitions {
+				t := &n.Automata[i].Transitions[pos]
+				pos++
+				if transition.Label == tauName {
+					if transition.Probability != nil {
+						return fmt.Errorf("line %d: automaton %s: silent transitions have no probabilities", transition.line, jAutomaton.Name)
+					}
+					t.Probability = 1
+					continue
+				}
+				switch probability := transition.Probability.(type) {
+				case nil:
+					return fmt.Errorf("line %d: automaton %s: no probability for the transition from state %q with label %q to state %q", transition.line, jAutomaton.Name, stateTransitions.From, transition.Label, transition.To)
+				case float64:
+					rational = false
+					t.Probability = probability
+				case string:
+					value, ok := new(big.Rat).SetString(probability)
+					if !ok {
+						return fmt.Errorf("line %d: automaton %s: cannot read probability %q", transition.line, jAutomaton.Name, probability)
+					}
+					t.Probability, _ = value.Float64()
+					denom := value.Denom()
+					denominator.Mul(denominator, new(big.Int).Div(denom, new(big.Int).GCD(nil, nil, denominator, denom)))
+				}
+			}
+		}
+	}
+	if rational && denominator.IsInt64() && denominator.Int64() <= math.MaxInt32 {
+		n.ProbabilityDenominator = int(denominator.Int64())
+	}
+	return nil
+}
+
+/*
+Set the costs of the transitions of an automaton built from the json
+representation, all the transitions with the same origin state and
+the same label get the same cost
+*/
+func (jAutomaton JSONAutomaton) assignCosts(a Automaton, labelIDs map[string]int, labelName func(int) string) error {
+	errorf := func(line int, format string, args ...interface{}) error {
+		return fmt.Errorf("line %d: automaton %s: %s", line, jAutomaton.Name, fmt.Sprintf(format, args...))
+	}
+
+	stateIDs := make(map[string]int)
+	for i := 0; i < a.NumStates; i++ {
+		stateIDs[a.stateNameOf(i)] = i
+	}
+	type key struct{ from, label int }
+	costs := make(map[key]float64)
+	lines := make(map[key]int)
+	for _, stateCosts := range jAutomaton.Costs.Content {
+		from, found := stateIDs[stateCosts.From]
+		if !found {
+			return errorf(stateCosts.line, "state %q is not declared", stateCosts.From)
+		}
+		for _, cost := range stateCosts.Costs {
+			label := TauLabel
+			if cost.Label != tauName {
+				var found bool
+				label, found = labelIDs[cost.Label]
+				if !found {
+					return errorf(cost.line, "label %q is not an input symbol", cost.Label)
+				}
+			}
+			k := key{from, label}
+			if _, found := costs[k]; found {
+				return errorf(cost.line, "cost of label %q from state %q given twice", cost.Label, stateCosts.From)
+			}
+			costs[k] = cost.Cost
+			lines[k] = cost.line
+		}
+	}
+
+	used := make(map[key]bool)
+	for i, transition := range a.Transitions {
+		k := key{transition.From, transition.Label}
+		cost, found := costs[k]
+		if !found {
+			return errorf(jAutomaton.line, "no cost for label %q from state %q", labelName(transition.Label), a.stateNameOf(transition.From))
+		}
+		a.Transitions[i].Cost = cost
+		used[k] = true
+	}
+	for k := range costs {
+		if !used[k] {
+			return errorf(lines[k], "cost of label %q from state %q without transitions", labelName(k.label), a.stateNameOf(k.from))
+		}
+	}
+	return nil
+}
+
+/*
+Numbers of names made of a prefix followed by a number, as
+in the output of noag, ok is false if some name is not of
+this form or if two names have the same number
+*/
+func defaultNumbers(names []string, prefix string) (numbers []int, ok bool) {
+	numbers = make([]int, len(names))
+	seen := make(map[int]bool)
+	for i, name := range names {
+		if !strings.HasPrefix(name, prefix) {
+			return nil, false
+		}
+		number, err := strconv.Atoi(name[len(prefix):])
+		if err != nil || number < 0 || seen[number] || strconv.Itoa(number) != name[len(prefix):] {
+			return nil, false
+		}
+		seen[number] = true
+		numbers[i] = number
+	}
+	return numbers, true
+}
+
+/*
+Check if some distinct numbers are 0, 1, ..., len(numbers)-1
+*/
+func isPermutation(numbers []int) bool {
+	for _, number := range numbers {
+		if number >= len(numbers) {
+			return false
+		}
+	}
+	return true
+}
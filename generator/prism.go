@@ -0,0 +1,270 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+Write a network in the PRISM modelling language, as a Markov
+decision process: each automaton is a module whose state is a
+variable ranging over its states (numbered as in the network, the
+initial state being 0), the shared labels are actions synchronising
+the modules which have them and the private labels and silent
+transitions are unsynchronised commands. The targets of a
+nondeterministic transition are distinct commands, the ones of a
+probabilistic transition are the updates of a single command. The
+global goal states are given by the label "goal" and the costs of
+weighted networks by the rewards "cost".
+*/
+func (n Network) WritePRISM(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	names := n.prismNames()
+	fmt.Fprintf(bw, "mdp\n")
+	for i, a := range n.Automata {
+		fmt.Fprintf(bw, "\n")
+		a.writePRISM(bw, i, n, names)
+	}
+
+	// global goal states
+	fmt.Fprintf(bw, "\nlabel \"goal\" = ")
+	for i, a := range n.Automata {
+		if i > 0 {
+			fmt.Fprintf(bw, " & ")
+		}
+		goalStates := make([]int, len(a.GoalStates))
+		copy(goalStates, a.GoalStates)
+		sort.Ints(goalStates)
+		goals := make([]string, len(goalStates))
+		for j, state := range goalStates {
+			goals[j] = fmt.Sprint(names.variables[i], "=", state)
+		}
+		switch len(goals) {
+		case 0:
+			fmt.Fprintf(bw, "false")
+		case 1:
+			fmt.Fprintf(bw, "%s", goals[0])
+		default:
+			fmt.Fprintf(bw, "(%s)", strings.Join(goals, " | "))
+		}
+	}
+	if len(n.Automata) == 0 {
+		fmt.Fprintf(bw, "true")
+	}
+	fmt.Fprintf(bw, ";\n")
+
+	// costs, the costs of the automata taking part
+	// in a synchronisation are summed
+	if n.Weighted {
+		fmt.Fprintf(bw, "\nrewards \"cost\"\n")
+		for i, a := range n.Automata {
+			transitions := a.sortedTransitions()
+			for j, transition := range transitions {
+				if j > 0 && transition.From == transitions[j-1].From && transition.Label == transitions[j-1].Label {
+					continue
+				}
+				fmt.Fprintf(bw, "\t[%s] %s=%d : %s;\n",
+					names.action(i, transition.Label), names.variables[i], transition.From,
+					strconv.FormatFloat(transition.Cost, 'g', -1, 64),
+				)
+			}
+		}
+		fmt.Fprintf(bw, "endrewards\n")
+	}
+
+	return bw.Flush()
+}
+
+/*
+Write the module of the automaton numbered id of the network n
+*/
+func (a Automaton) writePRISM(w *bufio.Writer, id int, n Network, names prismNames) {
+	variable := names.variables[id]
+	fmt.Fprintf(w, "module %s\n", names.modules[id])
+	if a.StateNames != nil {
+		// states keep their names in comments only
+		for i, name := range a.StateNames {
+			fmt.Fprintf(w, "\t// %s=%d: %s\n", variable, i, name)
+		}
+	}
+	fmt.Fprintf(w, "\t%s : [0..%d] init 0;\n", variable, a.NumStates-1)
+
+	transitions := a.sortedTransitions()
+	for i := 0; i < len(transitions); {
+		transition := transitions[i]
+		action := names.action(id, transition.Label)
+
+		// the transitions from a state with a label form a distribution
+		// in probabilistic networks, silent transitions excepted
+		if !n.Probabilistic || transition.Label == TauLabel {
+			fmt.Fprintf(w, "\t[%s] %s=%d -> (%s'=%d);\n", action, variable, transition.From, variable, transition.To)
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(transitions) && transitions[j].From == transition.From && transitions[j].Label == transition.Label {
+			j++
+		}
+		updates := make([]string, 0, j-i)
+		for _, target := range transitions[i:j] {
+			updates = append(updates, fmt.Sprintf("%s:(%s'=%d)", n.probabilityString(target.Probability), variable, target.To))
+		}
+		fmt.Fprintf(w, "\t[%s] %s=%d -> %s;\n", action, variable, transition.From, strings.Join(updates, " + "))
+		i = j
+	}
+
+	fmt.Fprintf(w, "endmodule\n")
+}
+
+/*
+Transitions of an automaton sorted by
+origin state, label and target state
+*/
+func (a Automaton) sortedTransitions() []Transition {
+	transitions := make([]Transition, len(a.Transitions))
+	copy(transitions, a.Transitions)
+	sortTransitions(transitions)
+	return transitions
+}
+
+/*
+Identifiers of the modules, state variables and
+actions of a network in the PRISM language
+*/
+type prismNames struct {
+	modules   []string
+	variables []string
+	// actions of the shared labels, and of the private
+	// labels when the network is weighted
+	actions map[int]string
+	// actions of the silent transitions of each
+	// automaton when the network is weighted
+	silent []string
+}
+
+/*
+Action of the transitions of the automaton numbered id with a label,
+empty for unsynchronised transitions
+*/
+func (names prismNames) action(id, label int) string {
+	if label == TauLabel {
+		if names.silent == nil {
+			return ""
+		}
+		return names.silent[id]
+	}
+	return names.actions[label]
+}
+
+/*
+Build distinct PRISM identifiers from the names of the automata
+and labels of the network, the state variable of the automaton
+numbered i being named xi. Private labels and silent transitions
+are unsynchronised, except in weighted networks where they need
+actions to get rewards: these actions are then used by one
+module only.
+*/
+func (n Network) prismNames() prismNames {
+	used := make(map[string]bool)
+	for _, keyword := range prismKeywords {
+		used[keyword] = true
+	}
+	identifier := func(name string) string {
+		name = prismIdentifier(name)
+		unique := name
+		for i := 1; used[unique]; i++ {
+			unique = fmt.Sprint(name, "_", i)
+		}
+		used[unique] = true
+		return unique
+	}
+
+	names := prismNames{
+		modules:   make([]string, len(n.Automata)),
+		variables: make([]string, len(n.Automata)),
+		actions:   make(map[int]string),
+	}
+	for i := range n.Automata {
+		names.variables[i] = identifier(fmt.Sprint("x", i))
+	}
+	for i, a := range n.Automata {
+		names.modules[i] = identifier(a.nameOf(i))
+	}
+
+	// number of automata having each label
+	numAutomata := make(map[int]int)
+	for _, a := range n.Automata {
+		for _, label := range a.Labels {
+			numAutomata[label]++
+		}
+	}
+	for _, a := range n.Automata {
+		for _, label := range a.Labels {
+			if _, found := names.actions[label]; !found && (numAutomata[label] > 1 || n.Weighted) {
+				names.actions[label] = identifier(n.LabelName(label))
+			}
+		}
+	}
+
+	if n.Weighted {
+		names.silent = make([]string, len(n.Automata))
+		for i := range n.Automata {
+			names.silent[i] = identifier(fmt.Sprint(tauName, "_", names.modules[i]))
+		}
+	}
+	return names
+}
+
+/*
+Replace the characters which cannot appear in
+a PRISM identifier by underscores
+*/
+func prismIdentifier(name string) string {
+	var b strings.Builder
+	for _, c := range name {
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			b.WriteRune(c)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	identifier := b.String()
+	if identifier == "" || (identifier[0] >= '0' && identifier[0] <= '9') {
+		identifier = "n_" + identifier
+	}
+	return identifier
+}
+
+// reserved words of the PRISM language
+var prismKeywords = []string{
+	"A", "bool", "clock", "const", "ctmc", "C", "double", "dtmc", "E",
+	"endinit", "endinvariant", "endmodule", "endobservables", "endrewards",
+	"endsystem", "false", "formula", "filter", "func", "F", "global", "G",
+	"init", "invariant", "I", "int", "label", "max", "mdp", "min", "module",
+	"X", "nondeterministic", "observable", "observables", "of", "Pmax",
+	"Pmin", "P", "pomdp", "popta", "probabilistic", "prob", "pta", "rate",
+	"rewards", "Rmax", "Rmin", "R", "S", "stochastic", "system", "true",
+	"U", "W",
+}
@@ -0,0 +1,228 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+/*
+Result of the exploration of the synchronous product of a network.
+Complete is false when the exploration was stopped by its budget
+before reaching a global goal state, otherwise Reachable being false
+proves that no global goal state is reachable. Plan is a shortest
+sequence of labels leading to a global goal state.
+*/
+type ProductResult struct {
+	Reachable      bool
+	Complete       bool
+	PlanLength     int
+	Plan           []int
+	ExploredStates int
+}
+
+/*
+Local view of an automaton for the product: successors of each
+state for each label, and goal states.
+*/
+type productAutomaton struct {
+	successors []map[int][]int
+	labels     []int
+	goal       []bool
+}
+
+/*
+Explore the synchronous product of a network in breadth first order,
+looking for a global state made of goal states only. A label
+can be used in a global state if all the automata having this
+label can use it from their local state, in which case they all
+change state simultaneously and the other automata do not move.
+Silent transitions are taken by one automaton alone, they appear
+in plans with the label TauLabel.
+At most budget global states are explored, 0 standing for no limit.
+*/
+func (n Network) Product(budget int) ProductResult {
+	automata := make([]productAutomaton, len(n.Automata))
+	holders := make(map[int][]int)
+	for i, a := range n.Automata {
+		automata[i].successors = make([]map[int][]int, a.NumStates)
+		for s := range automata[i].successors {
+			automata[i].successors[s] = make(map[int][]int)
+		}
+		for _, t := range a.Transitions {
+			automata[i].successors[t.From][t.Label] = append(automata[i].successors[t.From][t.Label], t.To)
+		}
+		automata[i].labels = a.Labels
+		automata[i].goal = make([]bool, a.NumStates)
+		for _, s := range a.GoalStates {
+			automata[i].goal[s] = true
+		}
+		for _, label := range a.Labels {
+			holders[label] = append(holders[label], i)
+		}
+	}
+
+	isGoal := func(state []int) bool {
+		for i, s := range state {
+			if !automata[i].goal[s] {
+				return false
+			}
+		}
+		return true
+	}
+
+	result := ProductResult{PlanLength: -1}
+
+	// explored global states, with the way they were reached
+	initial := make([]int, len(n.Automata))
+	keys := []string{encodeGlobalState(initial)}
+	parents := []int{-1}
+	via := []int{-1}
+	known := map[string]int{keys[0]: 0}
+
+	goal := -1
+	if isGoal(initial) {
+		goal = 0
+	}
+	state := make([]int, len(n.Automata))
+	for current := 0; goal < 0 && current < len(keys); current++ {
+		decodeGlobalState(keys[current], state)
+
+		// labels usable from some local state, in a fixed order
+		// so that the plan found does not change between runs
+		seen := make(map[int]bool)
+		candidates := make([]int, 0)
+		for i, s := range state {
+			for label := range automata[i].successors[s] {
+				if !seen[label] {
+					seen[label] = true
+					candidates = append(candidates, label)
+				}
+			}
+		}
+		sort.Ints(candidates)
+
+		// use a label with the automata moving with it,
+		// true if a global goal state is reached
+		use := func(label int, moving []int) bool {
+			for _, i := range moving {
+				if len(automata[i].successors[state[i]][label]) == 0 {
+					return false
+				}
+			}
+
+			// all the combinations of local successors
+			choices := make([]int, len(moving))
+			for {
+				next := make([]int, len(state))
+				copy(next, state)
+				for k, i := range moving {
+					next[i] = automata[i].successors[state[i]][label][choices[k]]
+				}
+				key := encodeGlobalState(next)
+				if _, found := known[key]; !found {
+					known[key] = len(keys)
+					keys = append(keys, key)
+					parents = append(parents, current)
+					via = append(via, label)
+					if isGoal(next) {
+						goal = len(keys) - 1
+						return true
+					}
+				}
+				// next combination
+				k := 0
+				for k < len(moving) {
+					choices[k]++
+					if choices[k] < len(automata[moving[k]].successors[state[moving[k]]][label]) {
+						break
+					}
+					choices[k] = 0
+					k++
+				}
+				if k == len(moving) {
+					return false
+				}
+			}
+		}
+
+		for _, label := range candidates {
+			if label != TauLabel {
+				// all the automata having the label must be able to use it
+				if use(label, holders[label]) {
+					break
+				}
+				continue
+			}
+			// silent transitions are local moves
+			for i := range state {
+				if use(TauLabel, []int{i}) {
+					break
+				}
+			}
+			if goal >= 0 {
+				break
+			}
+		}
+
+		if budget > 0 && len(keys) >= budget && goal < 0 {
+			result.ExploredStates = len(keys)
+			return result
+		}
+	}
+
+	result.ExploredStates = len(keys)
+	if goal < 0 {
+		result.Complete = true
+		return result
+	}
+
+	result.Reachable = true
+	result.Complete = true
+	for s := goal; parents[s] >= 0; s = parents[s] {
+		result.Plan = append(result.Plan, via[s])
+	}
+	for i, j := 0, len(result.Plan)-1; i < j; i, j = i+1, j-1 {
+		result.Plan[i], result.Plan[j] = result.Plan[j], result.Plan[i]
+	}
+	result.PlanLength = len(result.Plan)
+	return result
+}
+
+/*
+Compact representation of a global state, usable as a map key
+*/
+func encodeGlobalState(state []int) string {
+	buf := make([]byte, len(state)*binary.MaxVarintLen64)
+	n := 0
+	for _, s := range state {
+		n += binary.PutUvarint(buf[n:], uint64(s))
+	}
+	return string(buf[:n])
+}
+
+func decodeGlobalState(key string, state []int) {
+	buf := []byte(key)
+	for i := range state {
+		s, n := binary.Uvarint(buf)
+		state[i] = int(s)
+		buf = buf[n:]
+	}
+}
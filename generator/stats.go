@@ -0,0 +1,244 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"math"
+)
+
+// above this number of automata, the diameter of the
+// interaction graph is only bounded from below
+const diameterExactLimit = 5000
+
+/*
+Statistics on a network: aggregate statistics on the
+automata and on the interaction graph, and statistics
+on each automaton
+*/
+type Statistics struct {
+	NumAutomata    int
+	NumStates      int
+	NumGoalStates  int
+	NumTransitions int
+	// transitions with the reserved label TauLabel
+	NumSilentTransitions int
+	NumLabels            int
+	NumSharedLabels      int
+	NumPrivateLabels     int
+	// part of the labels used by only one automaton
+	PrivateLabelRatio float64
+	// number of transitions leaving each state
+	OutDegrees DegreeStatistics
+	// connected components of the interaction graph
+	NumComponents int
+	Degrees       DegreeStatistics
+	// largest distance between two automata of the same
+	// component of the interaction graph, a lower bound
+	// when DiameterExact is false
+	Diameter      int
+	DiameterExact bool
+	// average of the local clustering coefficients
+	// of the automata in the interaction graph
+	ClusteringCoefficient float64
+	// log10 of the product of the numbers of states,
+	// which bounds the number of states of the product
+	ProductSizeBoundLog10 float64
+	Automata              []AutomatonStatistics `json:",omitempty"`
+}
+
+/*
+Statistics on an automaton of a network
+*/
+type AutomatonStatistics struct {
+	Name                 string
+	NumStates            int
+	NumGoalStates        int
+	NumTransitions       int
+	NumSilentTransitions int
+	NumLabels            int
+	NumSharedLabels      int
+	NumPrivateLabels     int
+	PrivateLabelRatio    float64
+	OutDegrees           DegreeStatistics
+	// number of neighbours in the interaction graph
+	Degree int
+}
+
+/*
+Compute statistics on a network
+*/
+func (n Network) Statistics() Statistics {
+	ig := n.InteractionGraph()
+	stats := Statistics{
+		NumAutomata:     len(n.Automata),
+		NumSharedLabels: len(ig.SharedLabels),
+		NumComponents:   len(ig.Components()),
+		Degrees:         ig.DegreeStatistics(),
+		Automata:        make([]AutomatonStatistics, len(n.Automata)),
+	}
+
+	numAutomata := make(map[int]int)
+	for _, a := range n.Automata {
+		for _, label := range a.Labels {
+			numAutomata[label]++
+		}
+	}
+	stats.NumLabels = len(numAutomata)
+	stats.NumPrivateLabels = stats.NumLabels - stats.NumSharedLabels
+	stats.PrivateLabelRatio = ratio(stats.NumPrivateLabels, stats.NumLabels)
+
+	outDegrees := make([]int, 0)
+	for i, a := range n.Automata {
+		aStats := AutomatonStatistics{
+			Name:           a.nameOf(i),
+			NumStates:      a.NumStates,
+			NumGoalStates:  len(a.GoalStates),
+			NumTransitions: len(a.Transitions),
+			NumLabels:      len(a.Labels),
+			Degree:         len(ig.Adjacency[i]),
+		}
+		for _, label := range a.Labels {
+			if numAutomata[label] == 1 {
+				aStats.NumPrivateLabels++
+			}
+		}
+		aStats.NumSharedLabels = aStats.NumLabels - aStats.NumPrivateLabels
+		aStats.PrivateLabelRatio = ratio(aStats.NumPrivateLabels, aStats.NumLabels)
+
+		aOutDegrees := make([]int, a.NumStates)
+		for _, transition := range a.Transitions {
+			aOutDegrees[transition.From]++
+			if transition.Label == TauLabel {
+				aStats.NumSilentTransitions++
+			}
+		}
+		aStats.OutDegrees = degreeStatistics(aOutDegrees)
+		outDegrees = append(outDegrees, aOutDegrees...)
+
+		stats.NumStates += aStats.NumStates
+		stats.NumGoalStates += aStats.NumGoalStates
+		stats.NumTransitions += aStats.NumTransitions
+		stats.NumSilentTransitions += aStats.NumSilentTransitions
+		stats.ProductSizeBoundLog10 += math.Log10(float64(a.NumStates))
+		stats.Automata[i] = aStats
+	}
+	stats.OutDegrees = degreeStatistics(outDegrees)
+
+	stats.Diameter, stats.DiameterExact = ig.Diameter()
+	stats.ClusteringCoefficient = ig.ClusteringCoefficient()
+
+	return stats
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+/*
+Largest distance between two automata in the same connected
+component of the interaction graph. For large graphs (more than
+5000 automata) the diameter is not computed exactly: the result
+is a lower bound obtained by repeated breadth first searches from
+the farthest automaton found so far, and exact is false.
+*/
+func (ig InteractionGraph) Diameter() (diameter int, exact bool) {
+	distances := make([]int, ig.NumAutomata)
+	queue := make([]int, 0, ig.NumAutomata)
+
+	// eccentricity of an automaton and the farthest automaton
+	eccentricity := func(start int) (int, int) {
+		for i := range distances {
+			distances[i] = -1
+		}
+		distances[start] = 0
+		queue = append(queue[:0], start)
+		farthest := start
+		for head := 0; head < len(queue); head++ {
+			i := queue[head]
+			for _, j := range ig.Adjacency[i] {
+				if distances[j] < 0 {
+					distances[j] = distances[i] + 1
+					queue = append(queue, j)
+					farthest = j
+				}
+			}
+		}
+		return distances[farthest], farthest
+	}
+
+	if ig.NumAutomata <= diameterExactLimit {
+		for i := 0; i < ig.NumAutomata; i++ {
+			if e, _ := eccentricity(i); e > diameter {
+				diameter = e
+			}
+		}
+		return diameter, true
+	}
+
+	// double sweeps from the first automaton of each component
+	for _, component := range ig.Components() {
+		start := component[0]
+		for sweep := 0; sweep < 4; sweep++ {
+			e, farthest := eccentricity(start)
+			if e > diameter {
+				diameter = e
+			}
+			start = farthest
+		}
+	}
+	return diameter, false
+}
+
+/*
+Average local clustering coefficient of the interaction graph: for
+each automaton, the part of the pairs of its neighbours which are
+neighbours, 0 for automata with less than two neighbours
+*/
+func (ig InteractionGraph) ClusteringCoefficient() float64 {
+	if ig.NumAutomata == 0 {
+		return 0
+	}
+	isNeighbour := make([]bool, ig.NumAutomata)
+	sum := 0.0
+	for _, neighbours := range ig.Adjacency {
+		degree := len(neighbours)
+		if degree < 2 {
+			continue
+		}
+		for _, j := range neighbours {
+			isNeighbour[j] = true
+		}
+		links := 0
+		for _, j := range neighbours {
+			for _, k := range ig.Adjacency[j] {
+				if k > j && isNeighbour[k] {
+					links++
+				}
+			}
+		}
+		for _, j := range neighbours {
+			isNeighbour[j] = false
+		}
+		sum += float64(2*links) / float64(degree*(degree-1))
+	}
+	return sum / float64(ig.NumAutomata)
+}
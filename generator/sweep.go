@@ -0,0 +1,255 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/*
+Specification of a family of configurations. Each parameter takes
+a list of values or a range of values, every combination of values
+is a point of the sweep, the first parameter varying the slowest.
+InstancesPerPoint networks are generated for each point, with seeds
+BaseSeed, BaseSeed + 1, ... so that the points can be compared on
+the same seeds.
+*/
+type Sweep struct {
+	Parameters        []SweepParameter
+	InstancesPerPoint int
+	BaseSeed          int64
+}
+
+/*
+Values taken by a field of the configuration in a sweep, either
+given as a list or as a range from From to To (included) by Step
+*/
+type SweepParameter struct {
+	Field  string
+	Values []interface{}
+	From   json.Number
+	To     json.Number
+	Step   json.Number
+}
+
+/*
+A network to generate in a sweep: the values of the parameters
+at its point, its seed and the resulting configuration
+*/
+type SweepInstance struct {
+	Point         int
+	Settings      []SweepSetting
+	Seed          int64
+	Configuration Configuration
+}
+
+type SweepSetting struct {
+	Field string
+	Value string
+}
+
+/*
+Read a sweep specification from a json file
+*/
+func ReadSweepFile(file string) (Sweep, error) {
+	var sweep Sweep
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return sweep, fmt.Errorf("cannot open sweep file %s: %w", file, err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&sweep); err != nil {
+		return sweep, fmt.Errorf("cannot parse sweep file %s: %w", file, err)
+	}
+
+	if sweep.InstancesPerPoint < 1 {
+		sweep.InstancesPerPoint = 1
+	}
+	if sweep.BaseSeed == 0 {
+		sweep.BaseSeed = 1
+	}
+
+	return sweep, nil
+}
+
+/*
+Instances of a sweep, point by point, the configuration of each
+instance is the base configuration with the values of the point
+and the seed of the instance. No correction is applied at this
+point, see New.
+*/
+func (sweep Sweep) Instances(base Configuration) ([]SweepInstance, error) {
+	values := make([][]string, len(sweep.Parameters))
+	for i, parameter := range sweep.Parameters {
+		var err error
+		values[i], err = parameter.values()
+		if err != nil {
+			return nil, err
+		}
+		if len(values[i]) == 0 {
+			return nil, fmt.Errorf("sweep parameter %s: empty range", parameter.Field)
+		}
+		// check the field and the values once
+		for _, value := range values[i] {
+			if err := base.Set(parameter.Field, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	instances := make([]SweepInstance, 0)
+	positions := make([]int, len(sweep.Parameters))
+	for point := 0; ; point++ {
+		config := base
+		settings := make([]SweepSetting, len(sweep.Parameters))
+		for i, parameter := range sweep.Parameters {
+			settings[i] = SweepSetting{Field: parameter.Field, Value: values[i][positions[i]]}
+			config.Set(settings[i].Field, settings[i].Value)
+		}
+		for k := 0; k < sweep.InstancesPerPoint; k++ {
+			instance := SweepInstance{
+				Point:         point,
+				Settings:      settings,
+				Seed:          sweep.BaseSeed + int64(k),
+				Configuration: config,
+			}
+			instance.Configuration.Seed = instance.Seed
+			instances = append(instances, instance)
+		}
+
+		// next point, the last parameter varying the fastest
+		i := len(positions) - 1
+		for i >= 0 && positions[i] == len(values[i])-1 {
+			positions[i] = 0
+			i--
+		}
+		if i < 0 {
+			break
+		}
+		positions[i]++
+	}
+
+	return instances, nil
+}
+
+/*
+Values taken by a parameter, as strings to be given to Configuration.Set
+*/
+func (parameter SweepParameter) values() ([]string, error) {
+	isRange := parameter.From != "" || parameter.To != ""
+	if parameter.Values != nil && isRange {
+		return nil, fmt.Errorf("sweep parameter %s: both Values and a range are given", parameter.Field)
+	}
+
+	if !isRange {
+		if len(parameter.Values) == 0 {
+			return nil, fmt.Errorf("sweep parameter %s: no values given", parameter.Field)
+		}
+		values := make([]string, len(parameter.Values))
+		for i, value := range parameter.Values {
+			switch v := value.(type) {
+			case string:
+				values[i] = v
+			case json.Number:
+				values[i] = v.String()
+			default:
+				return nil, fmt.Errorf("sweep parameter %s: value %v is neither a number nor a string", parameter.Field, value)
+			}
+		}
+		return values, nil
+	}
+
+	if parameter.From == "" || parameter.To == "" {
+		return nil, fmt.Errorf("sweep parameter %s: a range needs both From and To", parameter.Field)
+	}
+	step := parameter.Step
+	if step == "" {
+		step = "1"
+	}
+
+	// integer range
+	from, errFrom := parameter.From.Int64()
+	to, errTo := parameter.To.Int64()
+	by, errBy := step.Int64()
+	if errFrom == nil && errTo == nil && errBy == nil {
+		if by <= 0 {
+			return nil, fmt.Errorf("sweep parameter %s: Step (%d) should be positive", parameter.Field, by)
+		}
+		values := make([]string, 0)
+		for v := from; v <= to; v += by {
+			values = append(values, strconv.FormatInt(v, 10))
+		}
+		return values, nil
+	}
+
+	// real range, written with as many decimals as the bounds and step
+	fromReal, errFrom := parameter.From.Float64()
+	toReal, errTo := parameter.To.Float64()
+	byReal, errBy := step.Float64()
+	if errFrom != nil || errTo != nil || errBy != nil {
+		return nil, fmt.Errorf("sweep parameter %s: From, To and Step should be numbers", parameter.Field)
+	}
+	if byReal <= 0 {
+		return nil, fmt.Errorf("sweep parameter %s: Step (%v) should be positive", parameter.Field, byReal)
+	}
+	decimals := 0
+	for _, number := range []json.Number{parameter.From, parameter.To, step} {
+		d := numDecimals(number.String())
+		if d < 0 || decimals < 0 {
+			decimals = -1
+		} else if d > decimals {
+			decimals = d
+		}
+	}
+	numValues := int(math.Floor((toReal-fromReal)/byReal+1e-9)) + 1
+	values := make([]string, 0, numValues)
+	for i := 0; i < numValues; i++ {
+		v := fromReal + float64(i)*byReal
+		if decimals < 0 {
+			values = append(values, strconv.FormatFloat(v, 'g', -1, 64))
+		} else {
+			values = append(values, strconv.FormatFloat(v, 'f', decimals, 64))
+		}
+	}
+	return values, nil
+}
+
+/*
+Number of decimals in the writing of a number,
+-1 if it is written with an exponent
+*/
+func numDecimals(number string) int {
+	if strings.ContainsAny(number, "eE") {
+		return -1
+	}
+	if pos := strings.Index(number, "."); pos >= 0 {
+		return len(number) - pos - 1
+	}
+	return 0
+}
@@ -0,0 +1,177 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"math"
+)
+
+// shapes of interaction graphs
+const (
+	TopologyRandom         = "random"
+	TopologyChain          = "chain"
+	TopologyRing           = "ring"
+	TopologyStar           = "star"
+	TopologyTree           = "tree"
+	TopologyGrid           = "grid"
+	TopologyErdosRenyi     = "erdos-renyi"
+	TopologyBarabasiAlbert = "barabasi-albert"
+	TopologyFromFile       = "file"
+)
+
+var topologies = []string{
+	TopologyRandom,
+	TopologyChain,
+	TopologyRing,
+	TopologyStar,
+	TopologyTree,
+	TopologyGrid,
+	TopologyErdosRenyi,
+	TopologyBarabasiAlbert,
+	TopologyFromFile,
+}
+
+/*
+Build the edges of an interaction graph on numAutomata automata
+with the shape given by the configuration. Automata are numbered
+from 0 to numAutomata-1, edges are pairs of distinct automata and
+there is no duplicated edge.
+*/
+func (gen *Generator) topologyEdges(numAutomata int) [][2]int {
+	config := gen.config
+
+	var edges [][2]int
+	switch config.Topology {
+	case TopologyChain:
+		edges = chainEdges(numAutomata)
+	case TopologyRing:
+		edges = chainEdges(numAutomata)
+		if numAutomata > 2 {
+			edges = append(edges, [2]int{numAutomata - 1, 0})
+		}
+	case TopologyStar:
+		for i := 1; i < numAutomata; i++ {
+			edges = append(edges, [2]int{0, i})
+		}
+	case TopologyTree:
+		for i := 1; i < numAutomata; i++ {
+			edges = append(edges, [2]int{(i - 1) / config.TopologyBranching, i})
+		}
+	case TopologyGrid:
+		width := config.TopologyGridWidth
+		if width == 0 {
+			width = int(math.Ceil(math.Sqrt(float64(numAutomata))))
+		}
+		for i := 0; i < numAutomata; i++ {
+			if (i+1)%width != 0 && i+1 < numAutomata {
+				edges = append(edges, [2]int{i, i + 1})
+			}
+			if i+width < numAutomata {
+				edges = append(edges, [2]int{i, i + width})
+			}
+		}
+	case TopologyErdosRenyi:
+		edges = gen.erdosRenyiEdges(numAutomata)
+	case TopologyBarabasiAlbert:
+		edges = gen.barabasiAlbertEdges(numAutomata)
+	}
+	return edges
+}
+
+func chainEdges(numAutomata int) [][2]int {
+	edges := make([][2]int, 0, numAutomata)
+	for i := 1; i < numAutomata; i++ {
+		edges = append(edges, [2]int{i - 1, i})
+	}
+	return edges
+}
+
+/*
+Random graph where each edge exists with probability
+TopologyEdgeProbability. Edges are enumerated by jumping over
+a geometrically distributed number of missing edges, so that
+sparse graphs are built in time proportional to their size.
+*/
+func (gen *Generator) erdosRenyiEdges(numAutomata int) [][2]int {
+	r := gen.rand
+	p := gen.config.TopologyEdgeProbability
+
+	edges := make([][2]int, 0)
+	if p <= 0 {
+		return edges
+	}
+	logq := math.Log(1 - p)
+	v, w := 1, -1
+	for v < numAutomata {
+		if p >= 1 {
+			w++
+		} else {
+			w += 1 + int(math.Floor(math.Log(1-r.Float64())/logq))
+		}
+		for w >= v && v < numAutomata {
+			w -= v
+			v++
+		}
+		if v < numAutomata {
+			edges = append(edges, [2]int{w, v})
+		}
+	}
+	return edges
+}
+
+/*
+Random graph built by preferential attachment: each new automaton
+is linked to TopologyAttachment distinct previous automata, chosen
+with a probability proportional to their degree.
+*/
+func (gen *Generator) barabasiAlbertEdges(numAutomata int) [][2]int {
+	r := gen.rand
+	m := gen.config.TopologyAttachment
+
+	edges := make([][2]int, 0)
+	// each automaton appears in ends once per incident edge
+	ends := make([]int, 0)
+	// the first automata are fully connected
+	initial := m + 1
+	if initial > numAutomata {
+		initial = numAutomata
+	}
+	for i := 0; i < initial; i++ {
+		for j := 0; j < i; j++ {
+			edges = append(edges, [2]int{j, i})
+			ends = append(ends, i, j)
+		}
+	}
+	for i := initial; i < numAutomata; i++ {
+		chosen := make(map[int]bool)
+		targets := make([]int, 0, m)
+		for len(targets) < m {
+			j := ends[r.Intn(len(ends))]
+			if !chosen[j] {
+				chosen[j] = true
+				targets = append(targets, j)
+			}
+		}
+		for _, j := range targets {
+			edges = append(edges, [2]int{j, i})
+			ends = append(ends, i, j)
+		}
+	}
+	return edges
+}
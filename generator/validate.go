@@ -0,0 +1,496 @@
+/*
+noag, generation of networks of automata
+Copyright (C) 2020 Loïg Jezequel
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+/*
+Result of the validation of a network file
+*/
+type ValidationReport struct {
+	Valid  bool              `json:"valid"`
+	Checks []ValidationCheck `json:"checks"`
+}
+
+/*
+Result of one of the checks of a validation, a check
+is skipped when the information it needs is missing
+*/
+type ValidationCheck struct {
+	Name     string   `json:"name"`
+	Passed   bool     `json:"passed"`
+	Skipped  bool     `json:"skipped,omitempty"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+// names of the validation checks
+const (
+	CheckDeterminism         = "determinism"
+	CheckInitialState        = "initial_state"
+	CheckDeclaredStates      = "declared_states"
+	CheckDeclaredLabels      = "declared_labels"
+	CheckUsedInputSymbols    = "used_input_symbols"
+	CheckConnectivity        = "connectivity"
+	CheckConfigurationBounds = "configuration_bounds"
+	CheckCosts               = "costs"
+	CheckProbabilities       = "probabilities"
+)
+
+// validation checks, in the order of the reports
+var checkNames = []string{
+	CheckDeterminism,
+	CheckInitialState,
+	CheckDeclaredStates,
+	CheckDeclaredLabels,
+	CheckUsedInputSymbols,
+	CheckConnectivity,
+	CheckConfigurationBounds,
+	CheckCosts,
+	CheckProbabilities,
+}
+
+/*
+Check that a network respects the properties promised for
+generated networks: determinism (or, for nondeterministic and probabilistic
+networks, at most MaxNumSuccessors distinct targets per state and label),
+declared initial, final and target states, declared and used
+labels, connected interaction graph, complete and non-negative
+costs for weighted networks, distributions over the targets for
+probabilistic networks and, when the network has metadata,
+bounds given by its configuration. The network is given by its json representation
+so that the files which cannot be converted to a Network can
+also be checked.
+*/
+func (jNetwork JSONNetwork) Validate() ValidationReport {
+	checks := make(map[string]*ValidationCheck)
+	for _, name := range checkNames {
+		checks[name] = &ValidationCheck{Name: name}
+	}
+	problem := func(check string, jAutomaton JSONAutomaton, line int, format string, args ...interface{}) {
+		checks[check].Problems = append(checks[check].Problems, fmt.Sprintf(
+			"line %d: automaton %s: %s", line, jAutomaton.Name, fmt.Sprintf(format, args...),
+		))
+	}
+
+	// number of targets allowed per state and label
+	maxNumSuccessors := 1
+	config := jNetwork.Metadata.Configuration
+	if jNetwork.hasMetadata && (config.Determinism == DeterminismNondeterministic || config.Determinism == DeterminismProbabilistic) {
+		maxNumSuccessors = config.MaxNumSuccessors
+	}
+
+	for _, jAutomaton := range jNetwork.Automata {
+		states := make(map[string]bool)
+		for _, state := range jAutomaton.States {
+			states[state] = true
+		}
+		labels := make(map[string]bool)
+		for _, label := range jAutomaton.InputSymbols {
+			labels[label] = true
+			if label == tauName {
+				problem(CheckDeclaredLabels, jAutomaton, jAutomaton.line, "label %q is reserved for silent transitions", label)
+			}
+		}
+
+		// initial state
+		if !states[jAutomaton.InitialState] {
+			problem(CheckInitialState, jAutomaton, jAutomaton.line, "initial state %q is not declared", jAutomaton.InitialState)
+		}
+
+		// final states
+		for _, state := range jAutomaton.FinalStates {
+			if !states[state] {
+				problem(CheckDeclaredStates, jAutomaton, jAutomaton.line, "final state %q is not declared", state)
+			}
+		}
+
+		// transitions
+		used := make(map[string]bool)
+		numTargets := make(map[[2]string]int)
+		seen := make(map[[3]string]bool)
+		for _, stateTransitions := range jAutomaton.Transitions.Content {
+			if !states[stateTransitions.From] {
+				problem(CheckDeclaredStates, jAutomaton, stateTransitions.line, "origin state %q is not declared", stateTransitions.From)
+			}
+			for _, transition := range stateTransitions.Transitions {
+				if !states[transition.To] {
+					problem(CheckDeclaredStates, jAutomaton, transition.line, "target state %q is not declared", transition.To)
+				}
+				if seen[[3]string{stateTransitions.From, transition.Label, transition.To}] {
+					problem(CheckDeterminism, jAutomaton, transition.line, "transition from state %q with label %q to state %q given twice", stateTransitions.From, transition.Label, transition.To)
+					continue
+				}
+				seen[[3]string{stateTransitions.From, transition.Label, transition.To}] = true
+				// silent transitions are not labelled by input
+				// symbols and can have several targets
+				if transition.Label == tauName {
+					continue
+				}
+				if !labels[transition.Label] {
+					problem(CheckDeclaredLabels, jAutomaton, transition.line, "label %q is not an input symbol", transition.Label)
+				}
+				used[transition.Label] = true
+				key := [2]string{stateTransitions.From, transition.Label}
+				numTargets[key]++
+				if numTargets[key] == maxNumSuccessors+1 {
+					if maxNumSuccessors == 1 {
+						problem(CheckDeterminism, jAutomaton, transition.line, "several transitions from state %q with label %q", stateTransitions.From, transition.Label)
+					} else {
+						problem(CheckDeterminism, jAutomaton, transition.line, "more than MaxNumSuccessors (%d) transitions from state %q with label %q", maxNumSuccessors, stateTransitions.From, transition.Label)
+					}
+				}
+			}
+		}
+
+		// input symbols
+		for _, label := range jAutomaton.InputSymbols {
+			if !used[label] {
+				problem(CheckUsedInputSymbols, jAutomaton, jAutomaton.line, "input symbol %q is not used", label)
+			}
+		}
+	}
+
+	// interaction graph, on the names of the labels
+	uf := newUnionFind(len(jNetwork.Automata))
+	firstAutomaton := make(map[string]int)
+	for i, jAutomaton := range jNetwork.Automata {
+		for _, label := range jAutomaton.InputSymbols {
+			if j, found := firstAutomaton[label]; found {
+				uf.union(i, j)
+			} else {
+				firstAutomaton[label] = i
+			}
+		}
+	}
+	components := make(map[int]bool)
+	for i := range jNetwork.Automata {
+		components[uf.find(i)] = true
+	}
+	// the interaction graph of a file is kept as it is, even
+	// when it is not connected
+	if jNetwork.hasMetadata && config.Topology == TopologyFromFile {
+		checks[CheckConnectivity].Skipped = true
+	} else if len(components) > 1 {
+		checks[CheckConnectivity].Problems = append(checks[CheckConnectivity].Problems, fmt.Sprintf(
+			"the interaction graph has %d connected components", len(components),
+		))
+	}
+
+	// configuration
+	if jNetwork.hasMetadata {
+		checks[CheckConfigurationBounds].Problems = jNetwork.checkBounds()
+	} else {
+		checks[CheckConfigurationBounds].Skipped = true
+	}
+
+	// costs
+	checks[CheckCosts].Problems, checks[CheckCosts].Skipped = jNetwork.checkCosts()
+
+	// probabilities
+	checks[CheckProbabilities].Problems, checks[CheckProbabilities].Skipped = jNetwork.checkProbabilities()
+
+	report := ValidationReport{Valid: true}
+	for _, name := range checkNames {
+		check := checks[name]
+		check.Passed = len(check.Problems) == 0
+		report.Valid = report.Valid && check.Passed
+		report.Checks = append(report.Checks, *check)
+	}
+	return report
+}
+
+/*
+Check the bounds of the configuration of a network which are
+guaranteed to be respected by the generation
+*/
+func (jNetwork JSONNetwork) checkBounds() []string {
+	config := jNetwork.Metadata.Configuration
+	problems := make([]string, 0)
+
+	if len(jNetwork.Automata) != config.NumAutomata {
+		problems = append(problems, fmt.Sprintf(
+			"%d automata instead of NumAutomata (%d)", len(jNetwork.Automata), config.NumAutomata,
+		))
+	}
+
+	// number of automata using each label
+	numAutomata := make(map[string]int)
+	for _, jAutomaton := range jNetwork.Automata {
+		for _, label := range jAutomaton.InputSymbols {
+			numAutomata[label]++
+		}
+	}
+
+	// the number of labels is only bounded for the random topology,
+	// the other ones giving a label per neighbour
+	boundedLabels := config.Topology == TopologyRandom
+
+	between := func(jAutomaton JSONAutomaton, what string, value, min, max int, minName, maxName string) {
+		if value < min {
+			problems = append(problems, fmt.Sprintf(
+				"line %d: automaton %s: %d %s, less than %s (%d)", jAutomaton.line, jAutomaton.Name, value, what, minName, min,
+			))
+		}
+		if max >= 0 && value > max {
+			problems = append(problems, fmt.Sprintf(
+				"line %d: automaton %s: %d %s, more than %s (%d)", jAutomaton.line, jAutomaton.Name, value, what, maxName, max,
+			))
+		}
+	}
+
+	for _, jAutomaton := range jNetwork.Automata {
+		between(jAutomaton, "states", len(jAutomaton.States),
+			config.MinNumStatesPerAutomaton, config.MaxNumStatesPerAutomaton,
+			"MinNumStatesPerAutomaton", "MaxNumStatesPerAutomaton")
+
+		minGoalStates := config.MinNumGoalStatesPerAutomaton
+		if minGoalStates > len(jAutomaton.States) {
+			minGoalStates = len(jAutomaton.States)
+		}
+		between(jAutomaton, "goal states", len(jAutomaton.FinalStates),
+			minGoalStates, config.MaxNumGoalStatesPerAutomaton,
+			"MinNumGoalStatesPerAutomaton", "MaxNumGoalStatesPerAutomaton")
+
+		maxLabels := -1
+		if boundedLabels {
+			maxLabels = config.MaxNumLabelsPerAutomaton
+		}
+		between(jAutomaton, "labels", len(jAutomaton.InputSymbols),
+			config.MinNumLabelsPerAutomaton, maxLabels,
+			"MinNumLabelsPerAutomaton", "MaxNumLabelsPerAutomaton")
+
+		numPrivateLabels := 0
+		for _, label := range jAutomaton.InputSymbols {
+			if numAutomata[label] == 1 {
+				numPrivateLabels++
+			}
+		}
+		between(jAutomaton, "private labels", numPrivateLabels,
+			config.minNumPrivateLabels(), -1,
+			"MinNumPrivateLabelsPerAutomaton", "")
+	}
+
+	return problems
+}
+
+/*
+Check the costs of a weighted network: one non-negative cost for
+each origin state and label of the transitions of each automaton
+and, when the network has metadata, costs drawn as described by
+its configuration. The check is skipped for networks without costs.
+*/
+func (jNetwork JSONNetwork) checkCosts() (problems []string, skipped bool) {
+	config := jNetwork.Metadata.Configuration
+	distribution := CostNone
+	if jNetwork.hasMetadata && config.CostDistribution != "" {
+		distribution = config.CostDistribution
+	}
+	weighted := distribution != CostNone
+	for _, jAutomaton := range jNetwork.Automata {
+		weighted = weighted || jAutomaton.Costs != nil
+	}
+	if !weighted {
+		return nil, true
+	}
+
+	problems = make([]string, 0)
+	problem := func(jAutomaton JSONAutomaton, line int, format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(
+			"line %d: automaton %s: %s", line, jAutomaton.Name, fmt.Sprintf(format, args...),
+		))
+	}
+
+	// cost of each label for the per-label distribution
+	labelCosts := make(map[string]float64)
+
+	for _, jAutomaton := range jNetwork.Automata {
+		if jAutomaton.Costs == nil {
+			problem(jAutomaton, jAutomaton.line, "no costs in a weighted network")
+			continue
+		}
+
+		// origin states and labels of transitions
+		pairs := make(map[[2]string]bool)
+		for _, stateTransitions := range jAutomaton.Transitions.Content {
+			for _, transition := range stateTransitions.Transitions {
+				pairs[[2]string{stateTransitions.From, transition.Label}] = true
+			}
+		}
+
+		given := make(map[[2]string]bool)
+		for _, stateCosts := range jAutomaton.Costs.Content {
+			for _, cost := range stateCosts.Costs {
+				key := [2]string{stateCosts.From, cost.Label}
+				if given[key] {
+					problem(jAutomaton, cost.line, "cost of label %q from state %q given twice", cost.Label, stateCosts.From)
+					continue
+				}
+				given[key] = true
+				if !pairs[key] {
+					problem(jAutomaton, cost.line, "cost of label %q from state %q without transitions", cost.Label, stateCosts.From)
+				}
+				if cost.Cost < 0 {
+					problem(jAutomaton, cost.line, "negative cost %v for label %q from state %q", cost.Cost, cost.Label, stateCosts.From)
+				}
+
+				// configuration
+				switch {
+				case distribution == CostNone:
+				case config.CostType == CostInt && cost.Cost != math.Trunc(cost.Cost):
+					problem(jAutomaton, cost.line, "cost %v for label %q from state %q is not an integer", cost.Cost, cost.Label, stateCosts.From)
+				case distribution == CostConstant:
+					if cost.Cost != config.MinCost {
+						problem(jAutomaton, cost.line, "cost %v for label %q from state %q instead of MinCost (%v)", cost.Cost, cost.Label, stateCosts.From, config.MinCost)
+					}
+				case cost.Cost < config.MinCost || cost.Cost > config.MaxCost:
+					problem(jAutomaton, cost.line, "cost %v for label %q from state %q not between MinCost (%v) and MaxCost (%v)", cost.Cost, cost.Label, stateCosts.From, config.MinCost, config.MaxCost)
+				}
+				if distribution == CostPerLabel {
+					if labelCost, found := labelCosts[cost.Label]; !found {
+						labelCosts[cost.Label] = cost.Cost
+					} else if cost.Cost != labelCost {
+						problem(jAutomaton, cost.line, "cost %v for label %q instead of %v as in the rest of the network", cost.Cost, cost.Label, labelCost)
+					}
+				}
+			}
+		}
+
+		for _, stateTransitions := range jAutomaton.Transitions.Content {
+			for _, transition := range stateTransitions.Transitions {
+				key := [2]string{stateTransitions.From, transition.Label}
+				if !given[key] {
+					problem(jAutomaton, transition.line, "no cost for label %q from state %q", transition.Label, stateTransitions.From)
+					given[key] = true
+				}
+			}
+		}
+	}
+
+	return problems, false
+}
+
+/*
+Check the probabilities of a probabilistic network: the transitions
+from a state with a label, silent transitions excepted, must have
+non-zero probabilities summing to 1 and, when the network has
+metadata, probabilities of the type given by its configuration.
+The check is skipped for networks without probabilities.
+*/
+func (jNetwork JSONNetwork) checkProbabilities() (problems []string, skipped bool) {
+	config := jNetwork.Metadata.Configuration
+	probabilistic := jNetwork.hasMetadata && config.Determinism == DeterminismProbabilistic
+	for _, jAutomaton := range jNetwork.Automata {
+		for _, stateTransitions := range jAutomaton.Transitions.Content {
+			for _, transition := range stateTransitions.Transitions {
+				probabilistic = probabilistic || transition.Probability != nil
+			}
+		}
+	}
+	if !probabilistic {
+		return nil, true
+	}
+
+	problems = make([]string, 0)
+	problem := func(jAutomaton JSONAutomaton, line int, format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(
+			"line %d: automaton %s: %s", line, jAutomaton.Name, fmt.Sprintf(format, args...),
+		))
+	}
+
+	for _, jAutomaton := range jNetwork.Automata {
+		// sum of the probabilities from each state with each label,
+		// exact for fractions and approximated for floats
+		var keys [][2]string
+		sums := make(map[[2]string]*big.Rat)
+		floatSums := make(map[[2]string]bool)
+		lines := make(map[[2]string]int)
+
+		for _, stateTransitions := range jAutomaton.Transitions.Content {
+			for _, transition := range stateTransitions.Transitions {
+				if transition.Label == tauName {
+					if transition.Probability != nil {
+						problem(jAutomaton, transition.line, "silent transition from state %q to state %q with a probability", stateTransitions.From, transition.To)
+					}
+					continue
+				}
+
+				var value *big.Rat
+				switch probability := transition.Probability.(type) {
+				case nil:
+					problem(jAutomaton, transition.line, "no probability for the transition from state %q with label %q to state %q", stateTransitions.From, transition.Label, transition.To)
+					continue
+				case float64:
+					value = new(big.Rat).SetFloat64(probability)
+					if jNetwork.hasMetadata && config.ProbabilityType == ProbabilityRational {
+						problem(jAutomaton, transition.line, "probability %v is not a fraction", probability)
+					}
+				case string:
+					var ok bool
+					value, ok = new(big.Rat).SetString(probability)
+					if !ok {
+						problem(jAutomaton, transition.line, "cannot read probability %q", probability)
+						continue
+					}
+					if jNetwork.hasMetadata && config.ProbabilityType == ProbabilityRational &&
+						config.ProbabilityGranularity > 0 &&
+						new(big.Int).Mod(big.NewInt(int64(config.ProbabilityGranularity)), value.Denom()).Sign() != 0 {
+						problem(jAutomaton, transition.line, "probability %s is not a multiple of 1/ProbabilityGranularity (1/%d)", probability, config.ProbabilityGranularity)
+					}
+					if jNetwork.hasMetadata && config.ProbabilityType == ProbabilityFloat {
+						problem(jAutomaton, transition.line, "probability %q is not a number", probability)
+					}
+				}
+				if value == nil || value.Sign() <= 0 || value.Cmp(big.NewRat(1, 1)) > 0 {
+					problem(jAutomaton, transition.line, "probability %v not in ]0, 1]", transition.Probability)
+				}
+
+				key := [2]string{stateTransitions.From, transition.Label}
+				if sums[key] == nil {
+					keys = append(keys, key)
+					sums[key] = new(big.Rat)
+					lines[key] = stateTransitions.line
+				}
+				if value != nil {
+					sums[key].Add(sums[key], value)
+				}
+				if _, isFloat := transition.Probability.(float64); isFloat {
+					floatSums[key] = true
+				}
+			}
+		}
+
+		for _, key := range keys {
+			sum := sums[key]
+			if floatSums[key] {
+				if total, _ := sum.Float64(); math.Abs(total-1) <= 1e-9 {
+					continue
+				}
+			} else if sum.Cmp(big.NewRat(1, 1)) == 0 {
+				continue
+			}
+			problem(jAutomaton, lines[key], "probabilities from state %q with label %q sum to %s instead of 1", key[0], key[1], sum.FloatString(6))
+		}
+	}
+
+	return problems, false
+}